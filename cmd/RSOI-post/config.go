@@ -0,0 +1,397 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andreymgn/RSOI-post/pkg/post"
+	"github.com/sirupsen/logrus"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// config holds service settings. Defaults are overridden by config file, environment and flags, in that order.
+// Every field has yaml key, flag named by dot-separated yaml path and environment variable named by env tag.
+// Variables named before the config existed are still read if set, legacyEnv tag names them
+type config struct {
+	Port                int           `yaml:"port" env:"PORT" usage:"gRPC port"`
+	HTTPPort            int           `yaml:"http-port" env:"HTTP_PORT" usage:"HTTP/JSON API port, 0 disables it"`
+	MetricsPort         int           `yaml:"metrics-port" env:"METRICS_PORT" usage:"Prometheus metrics port, 0 disables it"`
+	JaegerAddr          string        `yaml:"jaeger-addr" env:"JAEGER_ADDR" legacyEnv:"JAEGER-ADDR" usage:"Jaeger agent address"`
+	HealthCheckInterval time.Duration `yaml:"health-check-interval" env:"HEALTH_CHECK_INTERVAL" usage:"how often database is pinged"`
+	ShutdownTimeout     time.Duration `yaml:"shutdown-timeout" env:"SHUTDOWN_TIMEOUT" usage:"how long shutdown waits for running requests"`
+	Storage             string        `yaml:"storage" env:"STORAGE" usage:"postgres, or memory for development without database"`
+	AutoMigrate         bool          `yaml:"auto-migrate" env:"AUTO_MIGRATE" usage:"apply pending schema migrations on start"`
+
+	DB struct {
+		Conn            string        `yaml:"conn" env:"CONN" secret:"true" usage:"Postgres connection string"`
+		MaxOpenConns    int           `yaml:"max-open-conns" env:"DB_MAX_OPEN_CONNS" usage:"maximum number of open connections, 0 means unlimited"`
+		MaxIdleConns    int           `yaml:"max-idle-conns" env:"DB_MAX_IDLE_CONNS" usage:"maximum number of idle connections, 0 keeps default"`
+		ConnMaxLifetime time.Duration `yaml:"conn-max-lifetime" env:"DB_CONN_MAX_LIFETIME" usage:"how long connection may be reused, 0 means forever"`
+		QueryTimeout    time.Duration `yaml:"query-timeout" env:"DB_QUERY_TIMEOUT" usage:"how long a datastore call may take unless request deadline is earlier"`
+	} `yaml:"db"`
+
+	Log struct {
+		Level  string `yaml:"level" env:"LOG_LEVEL" usage:"debug, info, warn or error"`
+		Format string `yaml:"format" env:"LOG_FORMAT" usage:"json or text"`
+	} `yaml:"log"`
+
+	TLS struct {
+		Mode     string `yaml:"mode" env:"TLS_MODE" usage:"tls, mtls or insecure"`
+		Cert     string `yaml:"cert" env:"TLS_CERT" usage:"server certificate file"`
+		Key      string `yaml:"key" env:"TLS_KEY" usage:"server private key file"`
+		ClientCA string `yaml:"client-ca" env:"TLS_CLIENT_CA" usage:"client CA bundle file, required in mtls mode"`
+	} `yaml:"tls"`
+
+	Posts struct {
+		MaxBodyLength     int           `yaml:"max-body-length" env:"MAX_BODY_LENGTH" usage:"maximum number of characters in text post body"`
+		DefaultPageSize   int32         `yaml:"default-page-size" env:"DEFAULT_PAGE_SIZE" usage:"page size used when request doesn't set it"`
+		MaxPageSize       int32         `yaml:"max-page-size" env:"MAX_PAGE_SIZE" usage:"maximum page size"`
+		PurgeRetention    time.Duration `yaml:"purge-retention" env:"PURGE_RETENTION" usage:"how long deleted posts are kept, 0 keeps them forever"`
+		PurgeInterval     time.Duration `yaml:"purge-interval" env:"PURGE_INTERVAL" usage:"how often deleted posts and expired idempotency keys are purged"`
+		IdempotencyKeyTTL time.Duration `yaml:"idempotency-key-ttl" env:"IDEMPOTENCY_KEY_TTL" usage:"how long CreatePost idempotency keys are kept"`
+	} `yaml:"posts"`
+}
+
+// redacted replaces secret values in printed config
+const redacted = "<redacted>"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func defaultConfig() *config {
+	c := new(config)
+	c.Storage = post.StoragePostgres
+	c.HealthCheckInterval = post.DefaultHealthCheckInterval
+	c.ShutdownTimeout = post.DefaultShutdownTimeout
+	c.DB.QueryTimeout = post.DefaultQueryTimeout
+	c.Log.Level = "info"
+	c.Log.Format = "json"
+	c.TLS.Mode = string(post.TLSServer)
+	c.TLS.Cert = post.DefaultCertFile
+	c.TLS.Key = post.DefaultKeyFile
+	c.Posts.MaxBodyLength = post.DefaultMaxBodyLength
+	c.Posts.DefaultPageSize = post.DefaultPageSize
+	c.Posts.MaxPageSize = post.DefaultMaxPageSize
+	c.Posts.PurgeInterval = post.DefaultPurgeInterval
+	c.Posts.IdempotencyKeyTTL = post.DefaultIdempotencyKeyTTL
+	return c
+}
+
+// configErrors lists every invalid setting
+type configErrors []string
+
+func (e configErrors) Error() string {
+	return strings.Join(e, "\n")
+}
+
+// setting is a leaf field of config
+type setting struct {
+	name  string
+	field reflect.StructField
+	value reflect.Value
+}
+
+// settings returns leaf fields of struct v, names are yaml paths joined by dots
+func settings(v reflect.Value, prefix string) []setting {
+	var result []setting
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name := prefix + strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if field.Type.Kind() == reflect.Struct {
+			result = append(result, settings(v.Field(i), name+".")...)
+			continue
+		}
+
+		result = append(result, setting{name, field, v.Field(i)})
+	}
+
+	return result
+}
+
+// set parses str into setting value
+func (s setting) set(str string) error {
+	if s.value.Type() == durationType {
+		d, err := time.ParseDuration(str)
+		if err != nil {
+			return fmt.Errorf("%s: invalid duration %q", s.name, str)
+		}
+
+		s.value.SetInt(int64(d))
+		return nil
+	}
+
+	switch s.value.Kind() {
+	case reflect.String:
+		s.value.SetString(str)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(str)
+		if err != nil {
+			return fmt.Errorf("%s: invalid boolean %q", s.name, str)
+		}
+
+		s.value.SetBool(b)
+	case reflect.Int, reflect.Int32:
+		n, err := strconv.ParseInt(str, 10, s.value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%s: invalid integer %q", s.name, str)
+		}
+
+		s.value.SetInt(n)
+	default:
+		return fmt.Errorf("%s: unsupported type %s", s.name, s.value.Type())
+	}
+
+	return nil
+}
+
+// String returns value as it is written in flags and config file
+func (s setting) String() string {
+	if s.value.Type() == durationType {
+		return time.Duration(s.value.Int()).String()
+	}
+
+	return fmt.Sprint(s.value.Interface())
+}
+
+// rawFlag keeps flag value as is, it's parsed after config file is read
+type rawFlag struct {
+	value  string
+	isBool bool
+}
+
+func (f *rawFlag) String() string {
+	if f == nil {
+		return ""
+	}
+
+	return f.value
+}
+
+func (f *rawFlag) Set(value string) error {
+	f.value = value
+	return nil
+}
+
+func (f *rawFlag) IsBoolFlag() bool {
+	return f.isBool
+}
+
+// loadConfig reads config from file set by -config flag or CONFIG variable, environment and flags, and checks it with validate.
+// It returns true if config should be printed instead of running the service
+func loadConfig(args []string, getenv func(string) string, validate func(*config) error) (*config, bool, error) {
+	c := defaultConfig()
+	all := settings(reflect.ValueOf(c).Elem(), "")
+
+	fs := flag.NewFlagSet("RSOI-post", flag.ContinueOnError)
+	configFile := fs.String("config", getenv("CONFIG"), "YAML config file (env CONFIG)")
+	printConfig := fs.Bool("print-config", false, "print resulting config with secrets redacted and exit")
+	for _, s := range all {
+		usage := fmt.Sprintf("%s (env %s)", s.field.Tag.Get("usage"), s.field.Tag.Get("env"))
+		fs.Var(&rawFlag{s.String(), s.value.Kind() == reflect.Bool}, s.name, usage)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, false, err
+	}
+
+	if *configFile != "" {
+		b, err := ioutil.ReadFile(*configFile)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if err := yaml.UnmarshalStrict(b, c); err != nil {
+			return nil, false, fmt.Errorf("%s: %v", *configFile, err)
+		}
+	}
+
+	var errs configErrors
+	for _, s := range all {
+		v := getenv(s.field.Tag.Get("env"))
+		if legacy := s.field.Tag.Get("legacyEnv"); v == "" && legacy != "" {
+			v = getenv(legacy)
+		}
+
+		if v != "" {
+			if err := s.set(v); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+
+	byName := make(map[string]setting, len(all))
+	for _, s := range all {
+		byName[s.name] = s
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		s, ok := byName[f.Name]
+		if !ok {
+			return
+		}
+
+		if err := s.set(f.Value.String()); err != nil {
+			errs = append(errs, err.Error())
+		}
+	})
+
+	if err := validate(c); err != nil {
+		errs = append(errs, err.(configErrors)...)
+	}
+
+	if len(errs) > 0 {
+		return nil, false, errs
+	}
+
+	return c, *printConfig, nil
+}
+
+// validate returns configErrors listing every invalid setting
+func (c *config) validate() error {
+	var errs configErrors
+	check := func(ok bool, msg string) {
+		if !ok {
+			errs = append(errs, msg)
+		}
+	}
+
+	validPort := func(port int) bool {
+		return port >= 0 && port <= 65535
+	}
+
+	check(c.Port > 0 && c.Port <= 65535, "port: must be between 1 and 65535")
+	check(validPort(c.HTTPPort), "http-port: must be between 0 and 65535")
+	check(validPort(c.MetricsPort), "metrics-port: must be between 0 and 65535")
+	check(c.HealthCheckInterval > 0, "health-check-interval: must be positive")
+	check(c.ShutdownTimeout > 0, "shutdown-timeout: must be positive")
+
+	switch c.Storage {
+	case post.StoragePostgres:
+		if err := c.validateDB(); err != nil {
+			errs = append(errs, err.(configErrors)...)
+		}
+	case post.StorageMemory:
+	default:
+		check(false, "storage: must be one of postgres, memory")
+	}
+
+	check(c.DB.MaxOpenConns >= 0, "db.max-open-conns: must not be negative")
+	check(c.DB.MaxIdleConns >= 0, "db.max-idle-conns: must not be negative")
+	check(c.DB.ConnMaxLifetime >= 0, "db.conn-max-lifetime: must not be negative")
+	check(c.DB.QueryTimeout > 0, "db.query-timeout: must be positive")
+
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		check(false, "log.level: must be one of debug, info, warn, error")
+	}
+
+	check(c.Log.Format == "json" || c.Log.Format == "text", "log.format: must be one of json, text")
+
+	switch post.TLSMode(c.TLS.Mode) {
+	case post.TLSInsecure:
+	case post.TLSServer, post.TLSMutual:
+		check(c.TLS.Cert != "", "tls.cert: must be set unless tls.mode is insecure")
+		check(c.TLS.Key != "", "tls.key: must be set unless tls.mode is insecure")
+		check(c.TLS.Mode != string(post.TLSMutual) || c.TLS.ClientCA != "", "tls.client-ca: must be set in mtls mode")
+	default:
+		check(false, "tls.mode: must be one of tls, mtls, insecure")
+	}
+
+	check(c.Posts.MaxBodyLength > 0, "posts.max-body-length: must be positive")
+	check(c.Posts.DefaultPageSize > 0, "posts.default-page-size: must be positive")
+	check(c.Posts.MaxPageSize >= c.Posts.DefaultPageSize, "posts.max-page-size: must not be less than posts.default-page-size")
+	check(c.Posts.PurgeRetention >= 0, "posts.purge-retention: must not be negative")
+	check(c.Posts.PurgeInterval > 0, "posts.purge-interval: must be positive")
+	check(c.Posts.IdempotencyKeyTTL > 0, "posts.idempotency-key-ttl: must be positive")
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// validateDB checks settings needed to connect to database, they are all migrate command needs
+func (c *config) validateDB() error {
+	if c.DB.Conn == "" {
+		return configErrors{"db.conn: must be set"}
+	}
+
+	return nil
+}
+
+// logger returns logger writing to stderr with configured level and format
+func (c *config) logger() *logrus.Logger {
+	log := logrus.New()
+	if level, err := logrus.ParseLevel(c.Log.Level); err == nil {
+		log.SetLevel(level)
+	}
+
+	if c.Log.Format == "json" {
+		log.Formatter = new(logrus.JSONFormatter)
+	}
+
+	return log
+}
+
+// postConfig returns posts service settings
+func (c *config) postConfig() post.Config {
+	return post.Config{
+		MaxBodyLength:       c.Posts.MaxBodyLength,
+		PurgeRetention:      c.Posts.PurgeRetention,
+		PurgeInterval:       c.Posts.PurgeInterval,
+		IdempotencyKeyTTL:   c.Posts.IdempotencyKeyTTL,
+		HTTPPort:            c.HTTPPort,
+		HealthCheckInterval: c.HealthCheckInterval,
+		MetricsPort:         c.MetricsPort,
+		TLSMode:             post.TLSMode(c.TLS.Mode),
+		CertFile:            c.TLS.Cert,
+		KeyFile:             c.TLS.Key,
+		ClientCAFile:        c.TLS.ClientCA,
+		DefaultPageSize:     c.Posts.DefaultPageSize,
+		MaxPageSize:         c.Posts.MaxPageSize,
+		MaxOpenConns:        c.DB.MaxOpenConns,
+		MaxIdleConns:        c.DB.MaxIdleConns,
+		ConnMaxLifetime:     c.DB.ConnMaxLifetime,
+		QueryTimeout:        c.DB.QueryTimeout,
+		ShutdownTimeout:     c.ShutdownTimeout,
+		Storage:             c.Storage,
+		AutoMigrate:         c.AutoMigrate,
+	}
+}
+
+// print returns config as YAML with secrets redacted
+func (c *config) print() (string, error) {
+	out := printable(reflect.ValueOf(c).Elem())
+	b, err := yaml.Marshal(out)
+	return string(b), err
+}
+
+// printable converts struct v to ordered YAML map
+func printable(v reflect.Value) yaml.MapSlice {
+	var out yaml.MapSlice
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		var value interface{}
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			value = printable(v.Field(i))
+		case field.Tag.Get("secret") == "true" && v.Field(i).String() != "":
+			value = redacted
+		case field.Type == durationType:
+			value = time.Duration(v.Field(i).Int()).String()
+		default:
+			value = v.Field(i).Interface()
+		}
+
+		out = append(out, yaml.MapItem{Key: key, Value: value})
+	}
+
+	return out
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(name string) string {
+		return vars[name]
+	}
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	f, err := ioutil.TempFile("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Remove(f.Name())
+	f.WriteString("port: 1000\nhttp-port: 1001\ndb:\n  conn: file\nposts:\n  max-page-size: 50\n")
+	f.Close()
+
+	args := []string{"-config", f.Name(), "-port", "3000", "-posts.purge-retention", "72h", "-auto-migrate"}
+	c, printConfig, err := loadConfig(args, env(map[string]string{"PORT": "2000", "HTTP_PORT": "2001", "TLS_MODE": "insecure", "JAEGER-ADDR": "jaeger:6831"}), (*config).validate)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if printConfig {
+		t.Errorf("print-config is not requested")
+	}
+
+	if c.Port != 3000 {
+		t.Errorf("flag must override env and file: got port %d", c.Port)
+	}
+
+	if c.HTTPPort != 2001 {
+		t.Errorf("env must override file: got http-port %d", c.HTTPPort)
+	}
+
+	if c.DB.Conn != "file" || c.Posts.MaxPageSize != 50 || c.TLS.Mode != "insecure" {
+		t.Errorf("unexpected config %+v", c)
+	}
+
+	if c.JaegerAddr != "jaeger:6831" {
+		t.Errorf("legacy variable must be read: got jaeger-addr %q", c.JaegerAddr)
+	}
+
+	if c.Posts.PurgeRetention != 72*time.Hour {
+		t.Errorf("unexpected purge retention %v", c.Posts.PurgeRetention)
+	}
+
+	if !c.AutoMigrate {
+		t.Errorf("boolean flag without value must be set")
+	}
+
+	if c.Posts.DefaultPageSize != 10 {
+		t.Errorf("unset field must keep default: got default-page-size %d", c.Posts.DefaultPageSize)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	args := []string{"-storage", "disk", "-http-port", "http", "-log.level", "verbose", "-tls.mode", "mtls", "-posts.default-page-size", "200"}
+	_, _, err := loadConfig(args, env(map[string]string{"PURGE_INTERVAL": "hourly"}), (*config).validate)
+	errs, ok := err.(configErrors)
+	if !ok {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	want := []string{"posts.purge-interval", "http-port", "port", "storage", "log.level", "tls.client-ca", "posts.max-page-size"}
+	if len(errs) != len(want) {
+		t.Fatalf("unexpected errors:\n%v", err)
+	}
+
+	for i, name := range want {
+		if !strings.HasPrefix(errs[i], name+":") {
+			t.Errorf("error %d: got %q want %s error", i, errs[i], name)
+		}
+	}
+}
+
+func TestPrintConfig(t *testing.T) {
+	c, printConfig, err := loadConfig([]string{"-print-config", "-port", "8080"}, env(map[string]string{"CONN": "postgres://user:secret@db/posts"}), (*config).validate)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if !printConfig {
+		t.Errorf("print-config is requested")
+	}
+
+	out, err := c.print()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if strings.Contains(out, "secret") || !strings.Contains(out, redacted) {
+		t.Errorf("connection string is not redacted:\n%s", out)
+	}
+
+	if !strings.Contains(out, "purge-interval: 1h0m0s") {
+		t.Errorf("durations must be printed as strings:\n%s", out)
+	}
+}
+
+func TestMemoryStorageConfig(t *testing.T) {
+	c, _, err := loadConfig([]string{"-port", "8080", "-storage", "memory"}, env(nil), (*config).validate)
+	if err != nil {
+		t.Fatalf("connection string must not be required for memory storage: %v", err)
+	}
+
+	if c.postConfig().Storage != "memory" {
+		t.Errorf("unexpected storage %q", c.postConfig().Storage)
+	}
+}
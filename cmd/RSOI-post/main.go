@@ -1,25 +1,50 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"os"
-	"strconv"
 )
 
 func main() {
-	conn := os.Getenv("CONN")
-	port, err := strconv.Atoi(os.Getenv("PORT"))
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:], os.Getenv, os.Stdout); err != nil {
+			log.Printf("migrate error: %v", err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	conf, printConfig, err := loadConfig(os.Args[1:], os.Getenv, (*config).validate)
 	if err != nil {
-		log.Println("PORT parse error")
+		if _, ok := err.(configErrors); ok {
+			log.Printf("invalid config:\n%v", err)
+		} else {
+			log.Printf("config load error: %v", err)
+		}
+
+		os.Exit(2)
+	}
+
+	if printConfig {
+		out, err := conf.print()
+		if err != nil {
+			log.Fatalf("config print error: %v", err)
+		}
+
+		fmt.Print(out)
 		return
 	}
 
-	jaegerAddr := os.Getenv("JAEGER-ADDR")
+	logger := conf.logger()
+	postConf := conf.postConfig()
+	postConf.Logger = logger
 
-	log.Printf("running post service on port %d\n", port)
-	err = runPost(port, conn, jaegerAddr)
+	logger.WithField("port", conf.Port).Info("running post service")
+	err = runPost(conf.Port, conf.DB.Conn, conf.JaegerAddr, postConf)
 
 	if err != nil {
-		log.Printf("finished with error %v", err)
+		logger.WithError(err).Error("finished with error")
 	}
 }
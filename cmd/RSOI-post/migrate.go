@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"text/tabwriter"
+	"time"
+
+	"github.com/andreymgn/RSOI-post/pkg/post"
+)
+
+const migrateUsage = "usage: RSOI-post migrate up|down|status [flags]"
+
+// runMigrate runs migrate subcommand with args following its name
+func runMigrate(args []string, getenv func(string) string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf(migrateUsage)
+	}
+
+	conf, _, err := loadConfig(args[1:], getenv, (*config).validateDB)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		n, err := post.MigrateUp(conf.DB.Conn)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("applied %d migrations", n)
+	case "down":
+		version, err := post.MigrateDown(conf.DB.Conn)
+		if err != nil {
+			return err
+		}
+
+		if version == 0 {
+			log.Println("no migrations to revert")
+		} else {
+			log.Printf("reverted migration %d", version)
+		}
+	case "status":
+		states, err := post.MigrationStatus(conf.DB.Conn)
+		if err != nil {
+			return err
+		}
+
+		printMigrationStatus(out, states)
+	default:
+		return fmt.Errorf(migrateUsage)
+	}
+
+	return nil
+}
+
+// printMigrationStatus writes table of migrations
+func printMigrationStatus(out io.Writer, states []post.MigrationState) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	applied := 0
+	for _, s := range states {
+		appliedAt := "pending"
+		if !s.AppliedAt.IsZero() {
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+			applied++
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+	}
+
+	w.Flush()
+	if applied == 0 {
+		fmt.Fprintln(out, "no migrations applied")
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreymgn/RSOI-post/pkg/post"
+)
+
+func TestRunMigrateUsage(t *testing.T) {
+	getenv := env(map[string]string{"CONN": "postgres://localhost/posts"})
+	for _, args := range [][]string{nil, {"sideways"}} {
+		if err := runMigrate(args, getenv, new(bytes.Buffer)); err == nil || err.Error() != migrateUsage {
+			t.Errorf("%v: unexpected error %v", args, err)
+		}
+	}
+
+	if _, ok := runMigrate([]string{"up"}, env(nil), new(bytes.Buffer)).(configErrors); !ok {
+		t.Errorf("missing connection string must be reported")
+	}
+}
+
+func TestPrintMigrationStatus(t *testing.T) {
+	var out bytes.Buffer
+	appliedAt := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+	printMigrationStatus(&out, []post.MigrationState{{Version: 1, Name: "initial schema", AppliedAt: appliedAt}, {Version: 2, Name: "next"}})
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+
+	if !strings.Contains(lines[1], "2018-10-01T12:00:00Z") || !strings.HasSuffix(lines[2], "pending") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
+
+func TestPrintMigrationStatusNoneApplied(t *testing.T) {
+	var out bytes.Buffer
+	printMigrationStatus(&out, []post.MigrationState{{Version: 1, Name: "initial schema"}})
+	if !strings.HasSuffix(out.String(), "no migrations applied\n") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
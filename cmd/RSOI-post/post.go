@@ -1,11 +1,15 @@
 package main
 
 import (
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/andreymgn/RSOI-post/pkg/post"
 	"github.com/andreymgn/RSOI/pkg/tracer"
 )
 
-func runPost(port int, connString, jaegerAddr string) error {
+func runPost(port int, connString, jaegerAddr string, conf post.Config) error {
 	tracer, closer, err := tracer.NewTracer("post", jaegerAddr)
 	if err != nil {
 		return err
@@ -13,10 +17,24 @@ func runPost(port int, connString, jaegerAddr string) error {
 
 	defer closer.Close()
 
-	server, err := post.NewServer(connString)
+	server, err := post.NewServer(connString, conf)
 	if err != nil {
 		return err
 	}
 
-	return server.Start(port, tracer)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- server.Start(port, tracer)
+	}()
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGTERM, syscall.SIGINT)
+	select {
+	case err := <-errc:
+		server.Shutdown()
+		return err
+	case sig := <-sigc:
+		conf.Logger.WithField("signal", sig.String()).Info("shutting down")
+		return server.Shutdown()
+	}
 }
@@ -0,0 +1,742 @@
+package post
+
+import (
+	"html"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/net/context"
+)
+
+// TestMemoryDBConformance checks in-memory datastore
+func TestMemoryDBConformance(t *testing.T) {
+	testDatastore(t, func(t *testing.T) datastore {
+		return newMemoryDB()
+	})
+
+	// unlike Postgres, memoryDB matches words only as written
+	t.Run("UnstemmedSearch", func(t *testing.T) {
+		db := newMemoryDB()
+		mustCreate(t, db, uuid.Nil, "gopher conference")
+		if results, err := db.searchPosts(context.Background(), "gophers", uuid.Nil, uuid.Nil, 10, 0); err != nil || len(results) != 0 {
+			t.Errorf("unexpected results %v %v", results, err)
+		}
+	})
+}
+
+// TestPostgresConformance checks Postgres datastore against database from TEST_POSTGRES_CONN.
+// All posts in that database are removed
+func TestPostgresConformance(t *testing.T) {
+	connString := os.Getenv("TEST_POSTGRES_CONN")
+	if connString == "" {
+		t.Skip("TEST_POSTGRES_CONN is not set")
+	}
+
+	postgres, err := newDB(connString)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer postgres.Close()
+	if _, err := migrateUp(postgres.DB); err != nil {
+		t.Fatal(err)
+	}
+
+	testDatastore(t, func(t *testing.T) datastore {
+		if _, err := postgres.Exec("TRUNCATE posts, post_revisions, votes, idempotency_keys"); err != nil {
+			t.Fatal(err)
+		}
+
+		return postgres
+	})
+}
+
+// TestPostgresSingleConnection checks that failed conditional writes don't need second connection
+func TestPostgresSingleConnection(t *testing.T) {
+	connString := os.Getenv("TEST_POSTGRES_CONN")
+	if connString == "" {
+		t.Skip("TEST_POSTGRES_CONN is not set")
+	}
+
+	postgres, err := newDB(connString)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer postgres.Close()
+	if _, err := migrateUp(postgres.DB); err != nil {
+		t.Fatal(err)
+	}
+
+	postgres.SetMaxOpenConns(1)
+	postgres.queryTimeout = 5 * time.Second
+	p := mustCreate(t, postgres, uuid.Nil, "single connection")[0]
+	title := "edited"
+	if _, err := postgres.updatePost(context.Background(), p.UID, postUpdate{title: &title}, p.Version+1); err != errVersionMismatch {
+		t.Errorf("stale update: got %v want %v", err, errVersionMismatch)
+	}
+
+	if _, err := postgres.updatePost(context.Background(), uuid.New(), postUpdate{title: &title}, 0); err != errNotFound {
+		t.Errorf("update of missing post: got %v want %v", err, errNotFound)
+	}
+}
+
+func TestQueryTimeout(t *testing.T) {
+	postgres := &db{queryTimeout: time.Millisecond}
+	ctx, done := postgres.withTimeout(context.Background())
+	<-ctx.Done()
+
+	err := errDummy
+	done(&err)
+	if err != context.DeadlineExceeded {
+		t.Errorf("error of timed out query: got %v want %v", err, context.DeadlineExceeded)
+	}
+
+	ctx, done = postgres.withTimeout(context.Background())
+	err = errNotFound
+	done(&err)
+	if err != errNotFound {
+		t.Errorf("error of finished query: got %v want %v", err, errNotFound)
+	}
+
+	if ctx.Err() != context.Canceled {
+		t.Errorf("query context must be released when query finishes")
+	}
+}
+
+// testDatastore runs conformance suite against datastores returned by newStore, every test gets empty datastore
+func testDatastore(t *testing.T, newStore func(t *testing.T) datastore) {
+	tests := []struct {
+		name string
+		test func(*testing.T, datastore)
+	}{
+		{"Ordering", testOrdering},
+		{"Pagination", testPagination},
+		{"CategoryFilter", testCategoryFilter},
+		{"Update", testUpdate},
+		{"EmptyUpdate", testEmptyUpdate},
+		{"NotFound", testNotFound},
+		{"DeleteRestorePurge", testDeleteRestorePurge},
+		{"Votes", testVotes},
+		{"Revisions", testRevisions},
+		{"Idempotency", testIdempotency},
+		{"Search", testSearch},
+		{"SearchHighlightEscaping", testSearchHighlightEscaping},
+		{"Concurrency", testConcurrency},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.test(t, newStore(t))
+		})
+	}
+}
+
+var conformanceCategory = uuid.New()
+
+// mustCreate creates link posts with titles in category
+func mustCreate(t *testing.T, db datastore, categoryUID uuid.UUID, titles ...string) []*Post {
+	ctx := context.Background()
+	posts := make([]*Post, len(titles))
+	for i, title := range titles {
+		p, created, err := db.createPost(ctx, &Post{UserUID: dummyUID, CategoryUID: categoryUID, Title: title, URL: "https://example.com", Kind: LinkPost}, "", time.Hour)
+		if err != nil || !created {
+			t.Fatalf("creating post: unexpected error %v", err)
+		}
+
+		posts[i] = p
+	}
+
+	return posts
+}
+
+// listAll returns all posts following page tokens
+func listAll(t *testing.T, list func(context.Context, listQuery) ([]*Post, error), q listQuery) []*Post {
+	var result []*Post
+	for i := 0; ; i++ {
+		page, err := list(context.Background(), q)
+		if err != nil {
+			t.Fatalf("listing posts: unexpected error %v", err)
+		}
+
+		result = append(result, page...)
+		if len(page) < int(q.pageSize) {
+			return result
+		}
+
+		if i > 100 {
+			t.Fatalf("listing posts doesn't end")
+		}
+
+		q.cursor = cursorAfter(page[len(page)-1], q.sort)
+	}
+}
+
+func uids(posts []*Post) []uuid.UUID {
+	result := make([]uuid.UUID, len(posts))
+	for i, p := range posts {
+		result[i] = p.UID
+	}
+
+	return result
+}
+
+func equalUIDs(t *testing.T, what string, got, want []uuid.UUID) {
+	if len(got) != len(want) {
+		t.Errorf("%s: got %d posts want %d", what, len(got), len(want))
+		return
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%s: post %d is %v want %v", what, i, got[i], want[i])
+		}
+	}
+}
+
+func testOrdering(t *testing.T, db datastore) {
+	ctx := context.Background()
+	posts := mustCreate(t, db, uuid.Nil, "a", "b", "c", "d", "e")
+	votes := [][]int{{1}, {1, 1, -1}, {-1}, {1, -1}, {}}
+	for i, values := range votes {
+		for _, v := range values {
+			if _, _, err := db.vote(ctx, posts[i].UID, uuid.New(), v); err != nil {
+				t.Fatalf("voting: unexpected error %v", err)
+			}
+		}
+	}
+
+	stored := make([]*Post, len(posts))
+	for i, p := range posts {
+		var err error
+		if stored[i], err = db.getOnePost(ctx, p.UID, false); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+
+	for _, o := range []sortOrder{sortNew, sortHot, sortTop, sortControversial} {
+		want := append([]*Post(nil), stored...)
+		sort.Slice(want, func(i, j int) bool {
+			return comparePosts(cursorAfter(want[i], o), cursorAfter(want[j], o)) < 0
+		})
+
+		got, err := db.getAllPosts(ctx, listQuery{pageSize: 10, sort: o})
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		equalUIDs(t, o.rankColumn(), uids(got), uids(want))
+	}
+
+	top, _ := db.getAllPosts(ctx, listQuery{pageSize: 1, sort: sortTop})
+	if len(top) != 1 || top[0].UID != posts[1].UID || top[0].Score() != 1 {
+		t.Errorf("unexpected top post %+v", top)
+	}
+
+	newest, _ := db.getAllPosts(ctx, listQuery{pageSize: 10})
+	for i := 1; i < len(newest); i++ {
+		if newest[i].CreatedAt.After(newest[i-1].CreatedAt) {
+			t.Errorf("post %d is newer than previous one", i)
+		}
+	}
+}
+
+func testPagination(t *testing.T, db datastore) {
+	ctx := context.Background()
+	posts := mustCreate(t, db, uuid.Nil, "a", "b", "c", "d", "e", "f")
+	all, err := db.getAllPosts(ctx, listQuery{pageSize: 10})
+	if err != nil || len(all) != len(posts) {
+		t.Fatalf("unexpected posts %v %v", all, err)
+	}
+
+	for _, pageSize := range []int32{1, 2, 3, 4, 6, 7} {
+		for _, o := range []sortOrder{sortNew, sortHot} {
+			got := listAll(t, db.getAllPosts, listQuery{pageSize: pageSize, sort: o})
+			want, _ := db.getAllPosts(ctx, listQuery{pageSize: 10, sort: o})
+			equalUIDs(t, "page tokens", uids(got), uids(want))
+		}
+
+		var got []*Post
+		for n := int32(0); ; n++ {
+			page, err := db.getAllPosts(ctx, listQuery{pageSize: pageSize, pageNumber: n})
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			got = append(got, page...)
+			if len(page) < int(pageSize) {
+				break
+			}
+		}
+
+		equalUIDs(t, "page numbers", uids(got), uids(all))
+	}
+
+	if page, err := db.getAllPosts(ctx, listQuery{pageSize: 3, pageNumber: 5}); err != nil || len(page) != 0 {
+		t.Errorf("page past the end must be empty: got %v %v", page, err)
+	}
+
+	if page, err := db.getAllPosts(ctx, listQuery{pageSize: 10, cursor: cursorAfter(all[len(all)-1], sortNew)}); err != nil || len(page) != 0 {
+		t.Errorf("page after the last post must be empty: got %v %v", page, err)
+	}
+
+	if page, err := db.getAllPosts(ctx, listQuery{pageSize: 10, since: time.Now().Add(time.Hour)}); err != nil || len(page) != 0 {
+		t.Errorf("no post is created after since: got %v %v", page, err)
+	}
+
+	page, err := db.getAllPosts(ctx, listQuery{pageSize: 10, omitBody: true})
+	if err != nil || len(page) != len(posts) {
+		t.Fatalf("unexpected posts %v %v", page, err)
+	}
+}
+
+func testCategoryFilter(t *testing.T, db datastore) {
+	ctx := context.Background()
+	inCategory := mustCreate(t, db, conformanceCategory, "a", "b", "c")
+	mustCreate(t, db, uuid.Nil, "d", "e")
+
+	got := listAll(t, func(ctx context.Context, q listQuery) ([]*Post, error) {
+		return db.getAllPostsByCategory(ctx, conformanceCategory, q)
+	}, listQuery{pageSize: 2})
+	if len(got) != len(inCategory) {
+		t.Fatalf("got %d posts in category want %d", len(got), len(inCategory))
+	}
+
+	listed := make(map[uuid.UUID]bool)
+	for _, p := range got {
+		listed[p.UID] = true
+		if p.CategoryUID != conformanceCategory {
+			t.Errorf("post %v is not in category", p.UID)
+		}
+	}
+
+	for _, p := range inCategory {
+		if !listed[p.UID] {
+			t.Errorf("post %v is not listed", p.UID)
+		}
+	}
+
+	all, _ := db.getAllPosts(ctx, listQuery{pageSize: 10})
+	if len(all) != 5 {
+		t.Errorf("got %d posts want 5", len(all))
+	}
+}
+
+func testUpdate(t *testing.T, db datastore) {
+	ctx := context.Background()
+	p := mustCreate(t, db, uuid.Nil, "title")[0]
+	if p.Version != 1 || p.Revision != 1 {
+		t.Errorf("new post must have version and revision 1: got %d %d", p.Version, p.Revision)
+	}
+
+	title, url := "new title", ""
+	if _, err := db.updatePost(ctx, p.UID, postUpdate{title: &title}, 5); err != errVersionMismatch {
+		t.Errorf("stale version: got %v want %v", err, errVersionMismatch)
+	}
+
+	version, err := db.updatePost(ctx, p.UID, postUpdate{title: &title}, p.Version)
+	if err != nil || version != 2 {
+		t.Fatalf("unexpected update result %v %v", version, err)
+	}
+
+	version, err = db.updatePost(ctx, p.UID, postUpdate{url: &url}, 0)
+	if err != nil || version != 3 {
+		t.Fatalf("unconditional update: unexpected result %v %v", version, err)
+	}
+
+	got, err := db.getOnePost(ctx, p.UID, false)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if got.Title != title || got.URL != "" || got.Version != 3 || got.Revision != 3 {
+		t.Errorf("unexpected updated post %+v", got)
+	}
+
+	if got.ModifiedAt.Before(got.CreatedAt) {
+		t.Errorf("modification time must not precede creation")
+	}
+
+	if err := db.deletePost(ctx, p.UID, 0); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if _, err := db.updatePost(ctx, p.UID, postUpdate{title: &title}, 0); err != errNotFound {
+		t.Errorf("updating deleted post: got %v want %v", err, errNotFound)
+	}
+}
+
+func testEmptyUpdate(t *testing.T, db datastore) {
+	ctx := context.Background()
+	p := mustCreate(t, db, uuid.Nil, "title")[0]
+	for i := 0; i < 2; i++ {
+		// retried update with expected version must succeed too
+		version, err := db.updatePost(ctx, p.UID, postUpdate{}, p.Version)
+		if err != nil || version != p.Version {
+			t.Fatalf("empty update: unexpected result %v %v", version, err)
+		}
+	}
+
+	if _, err := db.updatePost(ctx, p.UID, postUpdate{}, p.Version+1); err != errVersionMismatch {
+		t.Errorf("empty update with stale version: got %v want %v", err, errVersionMismatch)
+	}
+
+	if _, err := db.updatePost(ctx, uuid.New(), postUpdate{}, 0); err != errNotFound {
+		t.Errorf("empty update of missing post: got %v want %v", err, errNotFound)
+	}
+
+	got, err := db.getOnePost(ctx, p.UID, false)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if got.Version != 1 || got.Revision != 1 {
+		t.Errorf("empty update must not change post: got %+v", got)
+	}
+
+	revisions, err := db.listRevisions(ctx, p.UID, 10, 0)
+	if err != nil || len(revisions) != 1 {
+		t.Errorf("empty update must not add revision: got %d revisions, error %v", len(revisions), err)
+	}
+}
+
+func testNotFound(t *testing.T, db datastore) {
+	ctx := context.Background()
+	uid := uuid.New()
+	title := "title"
+	if _, err := db.getOnePost(ctx, uid, true); err != errNotFound {
+		t.Errorf("getOnePost: got %v want %v", err, errNotFound)
+	}
+
+	if _, err := db.updatePost(ctx, uid, postUpdate{title: &title}, 0); err != errNotFound {
+		t.Errorf("updatePost: got %v want %v", err, errNotFound)
+	}
+
+	if err := db.deletePost(ctx, uid, 1); err != errNotFound {
+		t.Errorf("deletePost: got %v want %v", err, errNotFound)
+	}
+
+	if err := db.restorePost(ctx, uid); err != errNotFound {
+		t.Errorf("restorePost: got %v want %v", err, errNotFound)
+	}
+
+	if err := db.purgePost(ctx, uid); err != errNotFound {
+		t.Errorf("purgePost: got %v want %v", err, errNotFound)
+	}
+
+	if exists, err := db.checkPostExists(ctx, uid); err != nil || exists {
+		t.Errorf("checkPostExists: got %v %v want false", exists, err)
+	}
+
+	if _, err := db.getPostOwner(ctx, uid); err != errNotFound {
+		t.Errorf("getPostOwner: got %v want %v", err, errNotFound)
+	}
+
+	if _, _, err := db.vote(ctx, uid, dummyUID, 1); err != errNotFound {
+		t.Errorf("vote: got %v want %v", err, errNotFound)
+	}
+
+	if _, _, err := db.removeVote(ctx, uid, dummyUID); err != errNotFound {
+		t.Errorf("removeVote: got %v want %v", err, errNotFound)
+	}
+
+	if _, err := db.listRevisions(ctx, uid, 10, 0); err != errNotFound {
+		t.Errorf("listRevisions: got %v want %v", err, errNotFound)
+	}
+
+	if _, err := db.getRevision(ctx, uid, 1); err != errNotFound {
+		t.Errorf("getRevision: got %v want %v", err, errNotFound)
+	}
+}
+
+func testDeleteRestorePurge(t *testing.T, db datastore) {
+	ctx := context.Background()
+	posts := mustCreate(t, db, uuid.Nil, "a", "b")
+	p := posts[0]
+	if err := db.deletePost(ctx, p.UID, 2); err != errVersionMismatch {
+		t.Errorf("stale version: got %v want %v", err, errVersionMismatch)
+	}
+
+	if err := db.deletePost(ctx, p.UID, 1); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if err := db.deletePost(ctx, p.UID, 0); err != errNotFound {
+		t.Errorf("deleting deleted post: got %v want %v", err, errNotFound)
+	}
+
+	if _, err := db.getOnePost(ctx, p.UID, false); err != errNotFound {
+		t.Errorf("deleted post must be hidden: got %v", err)
+	}
+
+	deleted, err := db.getOnePost(ctx, p.UID, true)
+	if err != nil || deleted.DeletedAt.IsZero() || deleted.Version != 2 {
+		t.Errorf("unexpected deleted post %+v %v", deleted, err)
+	}
+
+	if exists, _ := db.checkPostExists(ctx, p.UID); exists {
+		t.Errorf("deleted post must not exist")
+	}
+
+	if owner, err := db.getPostOwner(ctx, p.UID); err != nil || owner != dummyUID.String() {
+		t.Errorf("owner of deleted post: got %v %v", owner, err)
+	}
+
+	if all, _ := db.getAllPosts(ctx, listQuery{pageSize: 10}); len(all) != 1 {
+		t.Errorf("deleted post must not be listed")
+	}
+
+	if err := db.restorePost(ctx, p.UID); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if err := db.restorePost(ctx, p.UID); err != errNotFound {
+		t.Errorf("restoring not deleted post: got %v want %v", err, errNotFound)
+	}
+
+	restored, err := db.getOnePost(ctx, p.UID, false)
+	if err != nil || !restored.DeletedAt.IsZero() || restored.Version != 3 {
+		t.Errorf("unexpected restored post %+v %v", restored, err)
+	}
+
+	db.deletePost(ctx, p.UID, 0)
+	if n, err := db.purgeDeleted(ctx, time.Now().Add(-time.Hour)); err != nil || n != 0 {
+		t.Errorf("recently deleted post must be kept: got %v %v", n, err)
+	}
+
+	if n, err := db.purgeDeleted(ctx, time.Now().Add(time.Hour)); err != nil || n != 1 {
+		t.Errorf("deleted post must be purged: got %v %v", n, err)
+	}
+
+	if _, err := db.getOnePost(ctx, p.UID, true); err != errNotFound {
+		t.Errorf("purged post must be gone: got %v", err)
+	}
+
+	if err := db.purgePost(ctx, posts[1].UID); err != nil {
+		t.Errorf("purging not deleted post: unexpected error %v", err)
+	}
+
+	if all, _ := db.getAllPosts(ctx, listQuery{pageSize: 10}); len(all) != 0 {
+		t.Errorf("got %d posts after purge want 0", len(all))
+	}
+}
+
+func testVotes(t *testing.T, db datastore) {
+	ctx := context.Background()
+	p := mustCreate(t, db, uuid.Nil, "a")[0]
+	user1, user2 := uuid.New(), uuid.New()
+	checks := []struct {
+		user      uuid.UUID
+		value     int
+		upvotes   int32
+		downvotes int32
+	}{
+		{user1, 1, 1, 0},
+		{user1, 1, 1, 0},
+		{user2, -1, 1, 1},
+		{user1, -1, 0, 2},
+		{user2, 0, 0, 1},
+		{user1, 0, 0, 0},
+	}
+
+	for i, c := range checks {
+		var up, down int32
+		var err error
+		if c.value == 0 {
+			up, down, err = db.removeVote(ctx, p.UID, c.user)
+		} else {
+			up, down, err = db.vote(ctx, p.UID, c.user, c.value)
+		}
+
+		if err != nil || up != c.upvotes || down != c.downvotes {
+			t.Errorf("vote %d: got %d %d %v want %d %d", i, up, down, err, c.upvotes, c.downvotes)
+		}
+	}
+
+	db.vote(ctx, p.UID, user1, 1)
+	db.vote(ctx, p.UID, user2, -1)
+	got, _ := db.getOnePost(ctx, p.UID, false)
+	if got.Upvotes != 1 || got.Downvotes != 1 || got.Controversy != controversy(1, 1) || got.HotRank != hotRank(0, got.CreatedAt) {
+		t.Errorf("unexpected post after votes %+v", got)
+	}
+
+	db.deletePost(ctx, p.UID, 0)
+	if _, _, err := db.vote(ctx, p.UID, user1, 1); err != errNotFound {
+		t.Errorf("voting for deleted post: got %v want %v", err, errNotFound)
+	}
+}
+
+func testRevisions(t *testing.T, db datastore) {
+	ctx := context.Background()
+	p := mustCreate(t, db, uuid.Nil, "v1")[0]
+	for _, title := range []string{"v2", "v3", "v4"} {
+		title := title
+		if _, err := db.updatePost(ctx, p.UID, postUpdate{title: &title}, 0); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+
+	want := []string{"v4", "v3", "v2", "v1"}
+	var got []string
+	for n := int32(0); n < 3; n++ {
+		page, err := db.listRevisions(ctx, p.UID, 3, n)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		for _, r := range page {
+			got = append(got, r.Title)
+		}
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got revisions %v want %v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("revision %d: got %q want %q", i, got[i], want[i])
+		}
+	}
+
+	r, err := db.getRevision(ctx, p.UID, 2)
+	if err != nil || r.Title != "v2" || r.Number != 2 || r.PostUID != p.UID {
+		t.Errorf("unexpected revision %+v %v", r, err)
+	}
+
+	if _, err := db.getRevision(ctx, p.UID, 5); err != errNotFound {
+		t.Errorf("missing revision: got %v want %v", err, errNotFound)
+	}
+}
+
+func testIdempotency(t *testing.T, db datastore) {
+	ctx := context.Background()
+	p := &Post{UserUID: dummyUID, Title: "a", Kind: TextPost, Body: "body"}
+	first, created, err := db.createPost(ctx, p, "key", time.Hour)
+	if err != nil || !created {
+		t.Fatalf("unexpected result %v %v", created, err)
+	}
+
+	retry, created, err := db.createPost(ctx, p, "key", time.Hour)
+	if err != nil || created || retry.UID != first.UID || retry.Body != "body" {
+		t.Errorf("retry must return the same post: got %+v %v %v", retry, created, err)
+	}
+
+	other, created, err := db.createPost(ctx, &Post{UserUID: uuid.New(), Title: "a"}, "key", time.Hour)
+	if err != nil || !created || other.UID == first.UID {
+		t.Errorf("key of other user must create new post: got %+v %v %v", other, created, err)
+	}
+
+	if n, err := db.purgeIdempotencyKeys(ctx, time.Now().Add(time.Hour)); err != nil || n != 2 {
+		t.Errorf("unexpected number of purged keys %v %v", n, err)
+	}
+
+	again, created, err := db.createPost(ctx, p, "key", time.Hour)
+	if err != nil || !created || again.UID == first.UID {
+		t.Errorf("purged key must create new post: got %+v %v %v", again, created, err)
+	}
+}
+
+func testSearch(t *testing.T, db datastore) {
+	ctx := context.Background()
+	mustCreate(t, db, uuid.Nil, "gopher conference", "gopher meetup")
+	inCategory := mustCreate(t, db, conformanceCategory, "gopher party")
+	mustCreate(t, db, uuid.Nil, "something else")
+
+	results, err := db.searchPosts(ctx, "gopher", uuid.Nil, uuid.Nil, 10, 0)
+	if err != nil || len(results) != 3 {
+		t.Fatalf("unexpected results %v %v", results, err)
+	}
+
+	for i := 1; i < len(results); i++ {
+		if results[i].Relevance > results[i-1].Relevance {
+			t.Errorf("result %d is more relevant than previous one", i)
+		}
+	}
+
+	results, err = db.searchPosts(ctx, "gopher", conformanceCategory, uuid.Nil, 10, 0)
+	if err != nil || len(results) != 1 || results[0].Post.UID != inCategory[0].UID {
+		t.Errorf("unexpected results in category %v %v", results, err)
+	}
+
+	if results[0].TitleHighlight != "<b>gopher</b> party" {
+		t.Errorf("unexpected highlight %q", results[0].TitleHighlight)
+	}
+
+	results, err = db.searchPosts(ctx, "gopher", uuid.Nil, uuid.New(), 10, 0)
+	if err != nil || len(results) != 0 {
+		t.Errorf("unexpected results of other user %v %v", results, err)
+	}
+
+	results, err = db.searchPosts(ctx, "gopher", uuid.Nil, uuid.Nil, 2, 1)
+	if err != nil || len(results) != 1 {
+		t.Errorf("unexpected second page %v %v", results, err)
+	}
+}
+
+func testSearchHighlightEscaping(t *testing.T, db datastore) {
+	title := `<script>alert("xss")</script> news & 'views'`
+	mustCreate(t, db, uuid.Nil, title)
+
+	results, err := db.searchPosts(context.Background(), "news", uuid.Nil, uuid.Nil, 10, 0)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("unexpected results %v %v", results, err)
+	}
+
+	highlight := results[0].TitleHighlight
+	if !strings.Contains(highlight, "<b>news</b>") {
+		t.Errorf("matching word must be highlighted: got %q", highlight)
+	}
+
+	// apart from highlighting tags title must be escaped
+	text := strings.NewReplacer("<b>", "", "</b>", "").Replace(highlight)
+	if strings.ContainsAny(text, `<>"'`) || html.UnescapeString(text) != title {
+		t.Errorf("highlighted title must be escaped: got %q", highlight)
+	}
+}
+
+func testConcurrency(t *testing.T, db datastore) {
+	ctx := context.Background()
+	p := mustCreate(t, db, uuid.Nil, "a")[0]
+	const n = 20
+	var wg sync.WaitGroup
+	errc := make(chan error, 3*n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, _, err := db.vote(ctx, p.UID, uuid.New(), 1); err != nil {
+				errc <- err
+			}
+
+			if _, _, err := db.createPost(ctx, &Post{UserUID: dummyUID, Title: "b"}, "concurrent", time.Hour); err != nil {
+				errc <- err
+			}
+
+			title := "c"
+			if _, err := db.updatePost(ctx, p.UID, postUpdate{title: &title}, 0); err != nil {
+				errc <- err
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	close(errc)
+	for err := range errc {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	got, err := db.getOnePost(ctx, p.UID, false)
+	if err != nil || got.Upvotes != n || got.Version != n+1 || got.Revision != n+1 {
+		t.Errorf("unexpected post after concurrent writes %+v %v", got, err)
+	}
+
+	if all, _ := db.getAllPosts(ctx, listQuery{pageSize: 10}); len(all) != 2 {
+		t.Errorf("concurrent requests with the same idempotency key must create one post: got %d posts want 2", len(all))
+	}
+}
@@ -0,0 +1,174 @@
+package post
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"net"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/lib/pq"
+	"golang.org/x/net/context"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorKind tells what went wrong regardless of datastore
+type errorKind int
+
+const (
+	kindInternal errorKind = iota
+	kindNotFound
+	kindConflict
+	kindDuplicate
+	kindConstraint
+	kindTooLong
+	kindUnavailable
+	kindTimeout
+	kindCanceled
+)
+
+// retryDelay is suggested to clients before retrying call failed for transient reason
+const retryDelay = time.Second
+
+// errorStatuses holds code, client-visible message and retryability of every error kind
+var errorStatuses = map[errorKind]struct {
+	code    codes.Code
+	message string
+	retry   bool
+}{
+	kindInternal:    {codes.Internal, "internal error", false},
+	kindNotFound:    {codes.NotFound, "post not found", false},
+	kindConflict:    {codes.Aborted, "conflicting concurrent change, retry the call", true},
+	kindDuplicate:   {codes.AlreadyExists, "already exists", false},
+	kindConstraint:  {codes.InvalidArgument, "value violates constraint", false},
+	kindTooLong:     {codes.InvalidArgument, "value is too long", false},
+	kindUnavailable: {codes.Unavailable, "datastore is unavailable", true},
+	kindTimeout:     {codes.DeadlineExceeded, "deadline exceeded", true},
+	kindCanceled:    {codes.Canceled, "request canceled", false},
+}
+
+// constraintFields maps database constraints to request fields they check.
+// Postgres names only constraint of check and foreign key violations, column is named only by not-null ones
+var constraintFields = map[string]string{
+	"posts_kind_check":    "kind",
+	"votes_value_check":   "direction",
+	"votes_post_uid_fkey": "postUid",
+}
+
+// columnFields maps database columns to request fields they are stored from
+var columnFields = map[string]string{
+	"title":        "title",
+	"url":          "url",
+	"body":         "body",
+	"kind":         "kind",
+	"user_uid":     "userUid",
+	"category_uid": "categoryUid",
+	"value":        "direction",
+	"key":          "idempotencyKey",
+}
+
+// classifiedError is returned to clients as status of its kind without internals, its cause is only logged
+type classifiedError struct {
+	kind errorKind
+	// field is request field caused the error, empty if unknown
+	field string
+	cause error
+}
+
+func (e *classifiedError) Error() string {
+	return e.cause.Error()
+}
+
+// GRPCStatus returns status sent to client instead of the cause
+func (e *classifiedError) GRPCStatus() *status.Status {
+	s := errorStatuses[e.kind]
+	st := status.New(s.code, s.message)
+
+	var details []proto.Message
+	if e.field != "" && s.code == codes.InvalidArgument {
+		details = append(details, &errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: e.field, Description: s.message}},
+		})
+	}
+
+	if s.retry {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(retryDelay)})
+	}
+
+	if len(details) == 0 {
+		return st
+	}
+
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st
+	}
+
+	return withDetails
+}
+
+// errorStatus converts error to status sent to client, see classify
+func errorStatus(err error) error {
+	return classify(err)
+}
+
+// classify determines kind of datastore error, unknown errors are internal
+func classify(err error) *classifiedError {
+	e := &classifiedError{kind: kindInternal, cause: err}
+	switch err {
+	case errNotFound:
+		e.kind = kindNotFound
+	case errVersionMismatch:
+		e.kind = kindConflict
+	case context.DeadlineExceeded:
+		e.kind = kindTimeout
+	case context.Canceled:
+		e.kind = kindCanceled
+	case driver.ErrBadConn, sql.ErrConnDone:
+		e.kind = kindUnavailable
+	}
+
+	if e.kind != kindInternal {
+		return e
+	}
+
+	switch err := err.(type) {
+	case *pq.Error:
+		e.kind = postgresKind(err.Code)
+		e.field = constraintFields[err.Constraint]
+		if e.field == "" {
+			e.field = columnFields[err.Column]
+		}
+	case net.Error:
+		e.kind = kindUnavailable
+	}
+
+	return e
+}
+
+// postgresKind determines kind of Postgres error by its code
+func postgresKind(code pq.ErrorCode) errorKind {
+	switch {
+	case code.Name() == "string_data_right_truncation":
+		return kindTooLong
+	case code.Name() == "unique_violation":
+		// retrying would hit the same row again
+		return kindDuplicate
+	case code.Class() == "40":
+		// serialization failures and deadlocks are resolved by retrying
+		return kindConflict
+	case code.Class() == "23":
+		return kindConstraint
+	case code.Name() == "query_canceled":
+		// statement_timeout expired
+		return kindTimeout
+	case code.Class() == "08", code.Class() == "53", code.Class() == "57":
+		// connection failures, exhausted resources and server shutdown
+		return kindUnavailable
+	default:
+		return kindInternal
+	}
+}
@@ -0,0 +1,69 @@
+package post
+
+import (
+	"database/sql/driver"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+	"golang.org/x/net/context"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		err   error
+		code  codes.Code
+		field string
+		retry bool
+	}{
+		{errDummy, codes.Internal, "", false},
+		{errNotFound, codes.NotFound, "", false},
+		{errVersionMismatch, codes.Aborted, "", true},
+		{context.DeadlineExceeded, codes.DeadlineExceeded, "", true},
+		{context.Canceled, codes.Canceled, "", false},
+		{driver.ErrBadConn, codes.Unavailable, "", true},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: errDummy}, codes.Unavailable, "", true},
+		{&pq.Error{Code: "22001", Message: "value too long for type character varying(80)"}, codes.InvalidArgument, "", false},
+		{&pq.Error{Code: "23502", Column: "title", Message: `null value in column "title" violates not-null constraint`}, codes.InvalidArgument, "title", false},
+		{&pq.Error{Code: "23505", Constraint: "posts_pkey"}, codes.AlreadyExists, "", false},
+		// fields are set as pq reads them from Postgres error response
+		{&pq.Error{Severity: "ERROR", Code: "23514", Message: `new row for relation "posts" violates check constraint "posts_kind_check"`, Schema: "public", Table: "posts", Constraint: "posts_kind_check"}, codes.InvalidArgument, "kind", false},
+		{&pq.Error{Severity: "ERROR", Code: "23514", Message: `new row for relation "votes" violates check constraint "votes_value_check"`, Schema: "public", Table: "votes", Constraint: "votes_value_check"}, codes.InvalidArgument, "direction", false},
+		{&pq.Error{Severity: "ERROR", Code: "23503", Message: `insert or update on table "votes" violates foreign key constraint "votes_post_uid_fkey"`, Schema: "public", Table: "votes", Constraint: "votes_post_uid_fkey"}, codes.InvalidArgument, "postUid", false},
+		{&pq.Error{Code: "40001"}, codes.Aborted, "", true},
+		{&pq.Error{Code: "57014"}, codes.DeadlineExceeded, "", true},
+		{&pq.Error{Code: "57P01"}, codes.Unavailable, "", true},
+		{&pq.Error{Code: "42P01", Message: `relation "posts" does not exist`}, codes.Internal, "", false},
+	}
+
+	for _, tt := range tests {
+		st := status.Convert(errorStatus(tt.err))
+		if st.Code() != tt.code {
+			t.Errorf("%v: got code %v want %v", tt.err, st.Code(), tt.code)
+		}
+
+		// errNotFound message is the same as client one
+		if tt.err != errNotFound && strings.Contains(st.Message(), tt.err.Error()) {
+			t.Errorf("%v: cause leaked to client message %q", tt.err, st.Message())
+		}
+
+		var field string
+		var retry bool
+		for _, detail := range st.Details() {
+			switch detail := detail.(type) {
+			case *errdetails.BadRequest:
+				field = detail.FieldViolations[0].Field
+			case *errdetails.RetryInfo:
+				retry = detail.RetryDelay.Seconds > 0
+			}
+		}
+
+		if field != tt.field || retry != tt.retry {
+			t.Errorf("%v: got field %q retry %v want %q %v", tt.err, field, retry, tt.field, tt.retry)
+		}
+	}
+}
@@ -0,0 +1,504 @@
+package post
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	pb "github.com/andreymgn/RSOI-post/pkg/post/proto"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/gorilla/mux"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"golang.org/x/net/context"
+	"google.golang.org/genproto/protobuf/field_mask"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// maxRequestBodySize limits size of HTTP request body in bytes
+const maxRequestBodySize = 1 << 20
+
+// gateway exposes posts service as HTTP/JSON API
+type gateway struct {
+	s           pb.PostServer
+	interceptor grpc.UnaryServerInterceptor
+}
+
+// gatewayHandler handles HTTP request by calling posts service
+type gatewayHandler func(r *http.Request) (proto.Message, error)
+
+var jsonMarshaler = &jsonpb.Marshaler{EmitDefaults: true}
+
+// newGateway returns HTTP handler which translates JSON requests to calls of s.
+// Calls go through interceptor like gRPC ones, tracing is continued from HTTP headers instead
+func newGateway(s pb.PostServer, tracer opentracing.Tracer, interceptor grpc.UnaryServerInterceptor) http.Handler {
+	g := &gateway{s, interceptor}
+	r := mux.NewRouter()
+	routes := []struct {
+		method  string
+		path    string
+		rpc     string
+		handler gatewayHandler
+	}{
+		{http.MethodGet, "/v1/posts", "ListPosts", g.listPosts},
+		{http.MethodPost, "/v1/posts", "CreatePost", g.createPost},
+		{http.MethodGet, "/v1/posts/{uid}", "GetPost", g.getPost},
+		{http.MethodPatch, "/v1/posts/{uid}", "UpdatePost", g.updatePost},
+		{http.MethodDelete, "/v1/posts/{uid}", "DeletePost", g.deletePost},
+		{http.MethodPost, "/v1/posts/{uid}/restore", "RestorePost", g.restorePost},
+		{http.MethodPost, "/v1/posts/{uid}/purge", "PurgePost", g.purgePost},
+		{http.MethodGet, "/v1/posts/{uid}/exists", "CheckPostExists", g.checkPostExists},
+		{http.MethodGet, "/v1/posts/{uid}/owner", "GetPostOwner", g.getPostOwner},
+		{http.MethodPost, "/v1/posts/{uid}/votes", "Vote", g.vote},
+		{http.MethodDelete, "/v1/posts/{uid}/votes/{userUid}", "RemoveVote", g.removeVote},
+		{http.MethodGet, "/v1/posts/{uid}/revisions", "ListPostRevisions", g.listPostRevisions},
+		{http.MethodGet, "/v1/posts/{uid}/revisions/{revision}", "GetPostRevision", g.getPostRevision},
+		{http.MethodGet, "/v1/categories/{uid}/posts", "ListPostsByCategory", g.listPostsByCategory},
+		{http.MethodGet, "/v1/search", "SearchPosts", g.searchPosts},
+	}
+
+	for _, route := range routes {
+		operation := route.method + " " + route.path
+		r.Handle(route.path, traced(tracer, operation, g.serve("/post.Post/"+route.rpc, route.handler))).Methods(route.method)
+	}
+
+	return r
+}
+
+// traced starts span for HTTP request continuing trace from request headers
+func traced(tracer opentracing.Tracer, operation string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parent, _ := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(r.Header))
+		span := tracer.StartSpan(operation, ext.RPCServerOption(parent))
+		defer span.Finish()
+
+		ext.HTTPMethod.Set(span, r.Method)
+		ext.HTTPUrl.Set(span, r.URL.String())
+		next.ServeHTTP(w, r.WithContext(opentracing.ContextWithSpan(r.Context(), span)))
+	})
+}
+
+// serve writes result of h as JSON, errors are written with HTTP status matching gRPC code.
+// Request is parsed and handled inside interceptor as call of method, so it's measured and logged like gRPC one
+func (g *gateway) serve(method string, h gatewayHandler) http.Handler {
+	info := &grpc.UnaryServerInfo{Server: g.s, FullMethod: method}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+		ctx := peer.NewContext(r.Context(), httpPeer(r))
+		body, err := g.interceptor(ctx, r, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return marshalResult(h(req.(*http.Request).WithContext(ctx)))
+		})
+
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, body.(string))
+	})
+}
+
+// httpAddr is address of HTTP client
+type httpAddr string
+
+func (a httpAddr) Network() string {
+	return "tcp"
+}
+
+func (a httpAddr) String() string {
+	return string(a)
+}
+
+// httpPeer describes HTTP client like gRPC describes its peers, so interceptors tell client address and identity
+func httpPeer(r *http.Request) *peer.Peer {
+	p := &peer.Peer{Addr: httpAddr(r.RemoteAddr)}
+	if r.TLS != nil {
+		p.AuthInfo = credentials.TLSInfo{State: *r.TLS}
+	}
+
+	return p
+}
+
+// marshalResult returns JSON of handler result
+func marshalResult(res proto.Message, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+
+	body, err := jsonMarshaler.MarshalToString(res)
+	if err != nil {
+		return "", errorStatus(err)
+	}
+
+	return body, nil
+}
+
+// writeError writes gRPC status as JSON with its details, such as field violations and retry delay
+func writeError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	body, err := jsonMarshaler.MarshalToString(st.Proto())
+	if err != nil {
+		// details of unknown type can't be marshaled, code and message are still useful
+		body, _ = jsonMarshaler.MarshalToString(status.New(st.Code(), st.Message()).Proto())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatusFromCode(st.Code()))
+	io.WriteString(w, body)
+}
+
+// httpStatusFromCode maps gRPC code to HTTP status
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// readBody reads request body limited with MaxBytesReader in serve
+func readBody(r *http.Request) ([]byte, error) {
+	data, err := ioutil.ReadAll(r.Body)
+	if err == nil {
+		return data, nil
+	}
+
+	// MaxBytesReader returns exactly limit bytes before its error, other errors come from broken or truncated body
+	if len(data) == maxRequestBodySize {
+		return nil, status.Errorf(codes.InvalidArgument, "request body is larger than %d bytes", maxRequestBodySize)
+	}
+
+	return nil, status.Error(codes.InvalidArgument, "invalid request body")
+}
+
+// decodeBody reads JSON request body into req, empty body leaves req unchanged
+func decodeBody(r *http.Request, req proto.Message) error {
+	data, err := readBody(r)
+	if err != nil {
+		return err
+	}
+
+	return unmarshalBody(data, req)
+}
+
+// unmarshalBody reads JSON into req, empty data leaves req unchanged
+func unmarshalBody(data []byte, req proto.Message) error {
+	err := jsonpb.Unmarshal(bytes.NewReader(data), req)
+	if err != nil && err != io.EOF {
+		return status.Error(codes.InvalidArgument, "invalid request body")
+	}
+
+	return nil
+}
+
+// extractFieldMask removes field mask written as string from JSON object and returns it parsed.
+// Proto3 JSON writes field mask as comma-separated paths, jsonpb reads only its object form, which is left in data
+func extractFieldMask(data []byte, name string) ([]byte, *field_mask.FieldMask, error) {
+	var fields map[string]json.RawMessage
+	if len(bytes.TrimSpace(data)) == 0 || json.Unmarshal(data, &fields) != nil {
+		// malformed body is reported by unmarshalBody
+		return data, nil, nil
+	}
+
+	var paths string
+	if raw, ok := fields[name]; !ok || json.Unmarshal(raw, &paths) != nil {
+		return data, nil, nil
+	}
+
+	mask := new(field_mask.FieldMask)
+	for _, path := range strings.Split(paths, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			mask.Paths = append(mask.Paths, path)
+		}
+	}
+
+	delete(fields, name)
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, nil, status.Error(codes.InvalidArgument, "invalid request body")
+	}
+
+	return data, mask, nil
+}
+
+// queryParams reads typed query parameters, first malformed one is kept in err
+type queryParams struct {
+	values url.Values
+	err    error
+}
+
+func newQueryParams(r *http.Request) *queryParams {
+	return &queryParams{values: r.URL.Query()}
+}
+
+func (q *queryParams) fail(name string) {
+	if q.err == nil {
+		q.err = status.Errorf(codes.InvalidArgument, "invalid query parameter %s", name)
+	}
+}
+
+func (q *queryParams) string(name string) string {
+	return q.values.Get(name)
+}
+
+func (q *queryParams) int32(name string) int32 {
+	s := q.values.Get(name)
+	if s == "" {
+		return 0
+	}
+
+	n, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		q.fail(name)
+	}
+
+	return int32(n)
+}
+
+func (q *queryParams) int64(name string) int64 {
+	s := q.values.Get(name)
+	if s == "" {
+		return 0
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		q.fail(name)
+	}
+
+	return n
+}
+
+func (q *queryParams) bool(name string) bool {
+	s := q.values.Get(name)
+	if s == "" {
+		return false
+	}
+
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		q.fail(name)
+	}
+
+	return b
+}
+
+// enum parses enum value by its name in any case
+func (q *queryParams) enum(name string, values map[string]int32) int32 {
+	s := q.values.Get(name)
+	if s == "" {
+		return 0
+	}
+
+	n, ok := values[strings.ToUpper(s)]
+	if !ok {
+		q.fail(name)
+	}
+
+	return n
+}
+
+func (g *gateway) listPosts(r *http.Request) (proto.Message, error) {
+	q := newQueryParams(r)
+	req := new(pb.ListPostsRequest)
+	req.PageSize = q.int32("pageSize")
+	req.PageNumber = q.int32("pageNumber")
+	req.PageToken = q.string("pageToken")
+	req.Sort = pb.SortOrder(q.enum("sort", pb.SortOrder_value))
+	req.Window = pb.TimeWindow(q.enum("window", pb.TimeWindow_value))
+	req.OmitBody = q.bool("omitBody")
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	return g.s.ListPosts(r.Context(), req)
+}
+
+func (g *gateway) listPostsByCategory(r *http.Request) (proto.Message, error) {
+	q := newQueryParams(r)
+	req := new(pb.ListPostsByCategoryRequest)
+	req.CategoryUid = mux.Vars(r)["uid"]
+	req.PageSize = q.int32("pageSize")
+	req.PageNumber = q.int32("pageNumber")
+	req.PageToken = q.string("pageToken")
+	req.Sort = pb.SortOrder(q.enum("sort", pb.SortOrder_value))
+	req.Window = pb.TimeWindow(q.enum("window", pb.TimeWindow_value))
+	req.OmitBody = q.bool("omitBody")
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	return g.s.ListPostsByCategory(r.Context(), req)
+}
+
+func (g *gateway) searchPosts(r *http.Request) (proto.Message, error) {
+	q := newQueryParams(r)
+	req := new(pb.SearchPostsRequest)
+	req.Query = q.string("q")
+	req.CategoryUid = q.string("categoryUid")
+	req.UserUid = q.string("userUid")
+	req.PageSize = q.int32("pageSize")
+	req.PageNumber = q.int32("pageNumber")
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	return g.s.SearchPosts(r.Context(), req)
+}
+
+func (g *gateway) getPost(r *http.Request) (proto.Message, error) {
+	q := newQueryParams(r)
+	req := new(pb.GetPostRequest)
+	req.Uid = mux.Vars(r)["uid"]
+	req.IncludeDeleted = q.bool("includeDeleted")
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	return g.s.GetPost(r.Context(), req)
+}
+
+func (g *gateway) createPost(r *http.Request) (proto.Message, error) {
+	req := new(pb.CreatePostRequest)
+	if err := decodeBody(r, req); err != nil {
+		return nil, err
+	}
+
+	return g.s.CreatePost(r.Context(), req)
+}
+
+func (g *gateway) updatePost(r *http.Request) (proto.Message, error) {
+	data, err := readBody(r)
+	if err != nil {
+		return nil, err
+	}
+
+	data, mask, err := extractFieldMask(data, "updateMask")
+	if err != nil {
+		return nil, err
+	}
+
+	req := new(pb.UpdatePostRequest)
+	if err := unmarshalBody(data, req); err != nil {
+		return nil, err
+	}
+
+	if mask != nil {
+		req.UpdateMask = mask
+	}
+
+	req.Uid = mux.Vars(r)["uid"]
+	return g.s.UpdatePost(r.Context(), req)
+}
+
+func (g *gateway) deletePost(r *http.Request) (proto.Message, error) {
+	q := newQueryParams(r)
+	req := new(pb.DeletePostRequest)
+	req.Uid = mux.Vars(r)["uid"]
+	req.ExpectedVersion = q.int64("expectedVersion")
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	return g.s.DeletePost(r.Context(), req)
+}
+
+func (g *gateway) restorePost(r *http.Request) (proto.Message, error) {
+	req := new(pb.RestorePostRequest)
+	req.Uid = mux.Vars(r)["uid"]
+	return g.s.RestorePost(r.Context(), req)
+}
+
+func (g *gateway) purgePost(r *http.Request) (proto.Message, error) {
+	req := new(pb.PurgePostRequest)
+	req.Uid = mux.Vars(r)["uid"]
+	return g.s.PurgePost(r.Context(), req)
+}
+
+func (g *gateway) checkPostExists(r *http.Request) (proto.Message, error) {
+	req := new(pb.CheckPostExistsRequest)
+	req.Uid = mux.Vars(r)["uid"]
+	return g.s.CheckPostExists(r.Context(), req)
+}
+
+func (g *gateway) getPostOwner(r *http.Request) (proto.Message, error) {
+	req := new(pb.GetPostOwnerRequest)
+	req.Uid = mux.Vars(r)["uid"]
+	return g.s.GetPostOwner(r.Context(), req)
+}
+
+func (g *gateway) vote(r *http.Request) (proto.Message, error) {
+	req := new(pb.VoteRequest)
+	if err := decodeBody(r, req); err != nil {
+		return nil, err
+	}
+
+	req.PostUid = mux.Vars(r)["uid"]
+	return g.s.Vote(r.Context(), req)
+}
+
+func (g *gateway) removeVote(r *http.Request) (proto.Message, error) {
+	req := new(pb.RemoveVoteRequest)
+	req.PostUid = mux.Vars(r)["uid"]
+	req.UserUid = mux.Vars(r)["userUid"]
+	return g.s.RemoveVote(r.Context(), req)
+}
+
+func (g *gateway) listPostRevisions(r *http.Request) (proto.Message, error) {
+	q := newQueryParams(r)
+	req := new(pb.ListPostRevisionsRequest)
+	req.Uid = mux.Vars(r)["uid"]
+	req.PageSize = q.int32("pageSize")
+	req.PageNumber = q.int32("pageNumber")
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	return g.s.ListPostRevisions(r.Context(), req)
+}
+
+func (g *gateway) getPostRevision(r *http.Request) (proto.Message, error) {
+	req := new(pb.GetPostRevisionRequest)
+	req.Uid = mux.Vars(r)["uid"]
+	revision, err := strconv.ParseInt(mux.Vars(r)["revision"], 10, 32)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid revision number")
+	}
+
+	req.Revision = int32(revision)
+	return g.s.GetPostRevision(r.Context(), req)
+}
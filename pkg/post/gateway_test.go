@@ -0,0 +1,171 @@
+package post
+
+import (
+	"database/sql/driver"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"golang.org/x/net/context"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGateway(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	h := newGateway(s, opentracing.NoopTracer{}, s.callInterceptor())
+	tests := []struct {
+		method string
+		target string
+		body   string
+		status int
+	}{
+		{http.MethodGet, "/v1/posts?pageSize=3&sort=hot&window=week", "", http.StatusOK},
+		{http.MethodGet, "/v1/posts?pageSize=three", "", http.StatusBadRequest},
+		{http.MethodGet, "/v1/posts?sort=best", "", http.StatusBadRequest},
+		{http.MethodGet, "/v1/categories/" + nilUIDString + "/posts", "", http.StatusOK},
+		{http.MethodGet, "/v1/posts/" + nilUIDString, "", http.StatusOK},
+		{http.MethodGet, "/v1/posts/invalid", "", http.StatusBadRequest},
+		{http.MethodPost, "/v1/posts", `{"title": "success", "userUid": "` + nilUIDString + `", "categoryUid": "` + nilUIDString + `"}`, http.StatusOK},
+		{http.MethodPost, "/v1/posts", `{"title": `, http.StatusBadRequest},
+		{http.MethodPatch, "/v1/posts/" + nilUIDString, `{"title": "Edited", "expectedVersion": "5"}`, http.StatusConflict},
+		{http.MethodDelete, "/v1/posts/" + nilUIDString, "", http.StatusOK},
+		{http.MethodPost, "/v1/posts/" + dummyUID.String() + "/restore", "", http.StatusNotFound},
+		{http.MethodPost, "/v1/posts/" + nilUIDString + "/votes", `{"userUid": "` + nilUIDString + `", "direction": "UP"}`, http.StatusOK},
+		{http.MethodGet, "/v1/posts/" + nilUIDString + "/revisions/1", "", http.StatusOK},
+		{http.MethodGet, "/v1/search?q=first", "", http.StatusOK},
+		{http.MethodPut, "/v1/posts/" + nilUIDString, "", http.StatusMethodNotAllowed},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.target, strings.NewReader(test.body))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != test.status {
+			t.Errorf("%s %s: unexpected status: got %v want %v", test.method, test.target, w.Code, test.status)
+		}
+	}
+}
+
+func TestHTTPStatusFromCode(t *testing.T) {
+	if httpStatusFromCode(codes.NotFound) != http.StatusNotFound {
+		t.Errorf("unexpected status for NotFound")
+	}
+
+	if httpStatusFromCode(codes.Internal) != http.StatusInternalServerError {
+		t.Errorf("unexpected status for Internal")
+	}
+}
+
+// errorDetails reads status written by writeError and returns its details
+func errorDetails(t *testing.T, w *httptest.ResponseRecorder) []interface{} {
+	st := new(spb.Status)
+	if err := jsonpb.Unmarshal(w.Body, st); err != nil {
+		t.Fatalf("error response isn't status: %v", err)
+	}
+
+	return status.FromProto(st).Details()
+}
+
+func TestGatewayErrorDetails(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	h := newGateway(s, opentracing.NoopTracer{}, s.callInterceptor())
+	body := `{"title": "` + strings.Repeat("a", maxTitleLength+1) + `", "userUid": "invalid", "categoryUid": "` + nilUIDString + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/posts", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: got %v want %v", w.Code, http.StatusBadRequest)
+	}
+
+	details := errorDetails(t, w)
+	badRequest, ok := details[0].(*errdetails.BadRequest)
+	if len(details) != 1 || !ok || len(badRequest.FieldViolations) != 2 {
+		t.Errorf("field violations must reach HTTP client: got %v", details)
+	}
+
+	w = httptest.NewRecorder()
+	writeError(w, errorStatus(driver.ErrBadConn))
+	details = errorDetails(t, w)
+	if retryInfo, ok := details[0].(*errdetails.RetryInfo); len(details) != 1 || !ok || retryInfo.RetryDelay.Seconds != 1 {
+		t.Errorf("retry delay must reach HTTP client: got %v", details)
+	}
+}
+
+func TestGatewayBodyLimit(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	h := newGateway(s, opentracing.NoopTracer{}, s.callInterceptor())
+	body := `{"title": "` + strings.Repeat("a", maxRequestBodySize) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/posts", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "request body is larger than") {
+		t.Errorf("too large body must be rejected: got %d %s", w.Code, w.Body)
+	}
+}
+
+func TestGatewayClearField(t *testing.T) {
+	db := newMemoryDB()
+	s := &Server{db: db, conf: testConfig}
+	h := newGateway(s, opentracing.NoopTracer{}, s.callInterceptor())
+	post := mustCreate(t, db, dummyUID, "link")[0]
+	target := "/v1/posts/" + post.UID.String()
+	for _, body := range []string{`{"url": "", "updateMask": "url"}`, `{"url": "", "updateMask": {"paths": ["url"]}}`} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, target, strings.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: unexpected status: got %d %s", body, w.Code, w.Body)
+		}
+
+		got, err := db.getOnePost(context.Background(), post.UID, false)
+		if err != nil || got.URL != "" {
+			t.Errorf("%s: url must be cleared: got %q, error %v", body, got.URL, err)
+		}
+
+		db.posts[post.UID].URL = "https://example.com"
+	}
+}
+
+// failingReader returns some data and then error, like body of disconnected client
+type failingReader struct {
+	data string
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestGatewayBrokenBody(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	h := newGateway(s, opentracing.NoopTracer{}, s.callInterceptor())
+	req := httptest.NewRequest(http.MethodPost, "/v1/posts", &failingReader{`{"title": "trunc`})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "invalid request body") {
+		t.Errorf("broken body must be reported as invalid: got %d %s", w.Code, w.Body)
+	}
+}
+
+func TestGatewayMetrics(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	h := newGateway(s, opentracing.NoopTracer{}, s.callInterceptor())
+	before := testutil.ToFloat64(rpcRequests.WithLabelValues("RestorePost", "NotFound"))
+	req := httptest.NewRequest(http.MethodPost, "/v1/posts/"+dummyUID.String()+"/restore", nil)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if got := testutil.ToFloat64(rpcRequests.WithLabelValues("RestorePost", "NotFound")) - before; got != 1 {
+		t.Errorf("HTTP call must be counted like gRPC one: got %v", got)
+	}
+}
@@ -0,0 +1,84 @@
+package post
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// serviceName is the name posts service is registered under in health server
+const serviceName = "post.Post"
+
+// newHealthServer returns health server reporting posts service as not serving
+func newHealthServer() *health.Server {
+	h := health.NewServer()
+	h.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	h.SetServingStatus(serviceName, healthpb.HealthCheckResponse_NOT_SERVING)
+	return h
+}
+
+// waitReady blocks until database schema check passes, it returns false if server was shut down before that
+func (s *Server) waitReady() bool {
+	for {
+		err := s.db.checkSchema(context.Background())
+		if err == nil {
+			return true
+		}
+
+		s.conf.Logger.WithError(err).Warn("database is not ready")
+		select {
+		case <-time.After(s.conf.HealthCheckInterval):
+		case <-s.done:
+			return false
+		}
+	}
+}
+
+// setServingStatus sets status of the whole server and posts service
+func (s *Server) setServingStatus(status healthpb.HealthCheckResponse_ServingStatus) {
+	s.health.SetServingStatus("", status)
+	s.health.SetServingStatus(serviceName, status)
+}
+
+// updateHealth sets serving status of posts service according to database availability
+func (s *Server) updateHealth() {
+	status := healthpb.HealthCheckResponse_SERVING
+	if err := s.db.ping(context.Background()); err != nil {
+		s.conf.Logger.WithError(err).Error("database ping failed")
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	// status set by Shutdown must not be overwritten
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+
+	s.setServingStatus(status)
+}
+
+// checkHealth marks service serving once database is ready and keeps status up to date
+func (s *Server) checkHealth() {
+	if !s.waitReady() {
+		return
+	}
+
+	s.updateHealth()
+
+	ticker := time.NewTicker(s.conf.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.done:
+			return
+		}
+
+		s.updateHealth()
+	}
+}
@@ -0,0 +1,72 @@
+package post
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestHealth(t *testing.T) {
+	mdb := &mockdb{}
+	s := &Server{db: mdb, conf: testConfig, health: newHealthServer()}
+	checkStatus := func(want healthpb.HealthCheckResponse_ServingStatus) {
+		resp, err := s.health.Check(context.Background(), &healthpb.HealthCheckRequest{Service: serviceName})
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		if resp.Status != want {
+			t.Errorf("unexpected status: got %v want %v", resp.Status, want)
+		}
+	}
+
+	checkStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+
+	s.waitReady()
+	s.updateHealth()
+	checkStatus(healthpb.HealthCheckResponse_SERVING)
+
+	mdb.down = true
+	s.updateHealth()
+	checkStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+
+	mdb.down = false
+	s.updateHealth()
+	checkStatus(healthpb.HealthCheckResponse_SERVING)
+}
+
+// slowPingDB is mockdb which pings until unblock is closed
+type slowPingDB struct {
+	mockdb
+	pinging chan struct{}
+	unblock chan struct{}
+}
+
+func (db *slowPingDB) ping(ctx context.Context) error {
+	close(db.pinging)
+	<-db.unblock
+	return nil
+}
+
+func TestHealthAfterShutdown(t *testing.T) {
+	db := &slowPingDB{pinging: make(chan struct{}), unblock: make(chan struct{})}
+	s := &Server{db: db, conf: testConfig, health: newHealthServer(), done: make(chan struct{})}
+	updated := make(chan struct{})
+	go func() {
+		s.updateHealth()
+		close(updated)
+	}()
+
+	<-db.pinging
+	if err := s.Shutdown(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	close(db.unblock)
+	<-updated
+	resp, err := s.health.Check(context.Background(), &healthpb.HealthCheckRequest{Service: serviceName})
+	if err != nil || resp.Status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("status set by Shutdown was overwritten: got %v %v", resp, err)
+	}
+}
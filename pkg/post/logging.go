@@ -0,0 +1,83 @@
+package post
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// traceID returns ID of trace ctx belongs to, empty if tracer doesn't expose it
+func traceID(ctx context.Context) string {
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return ""
+	}
+
+	// Jaeger span context is printed as trace-id:span-id:parent-id:flags
+	if sc, ok := span.Context().(fmt.Stringer); ok {
+		return strings.SplitN(sc.String(), ":", 2)[0]
+	}
+
+	return ""
+}
+
+// serverFault tells if code means the call failed because of server rather than request
+func serverFault(code codes.Code) bool {
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// logCall records call of method by client at peerAddr started at start, with cause of classified error if any
+func logCall(ctx context.Context, log logrus.FieldLogger, method, peerAddr string, start time.Time, err error) {
+	code := status.Code(err)
+	entry := log.WithFields(logrus.Fields{
+		"method":   method,
+		"peer":     peerAddr,
+		"duration": time.Since(start).Seconds(),
+		"code":     code.String(),
+	})
+
+	if id := traceID(ctx); id != "" {
+		entry = entry.WithField("trace_id", id)
+	}
+
+	if identity := clientIdentity(ctx); identity != "" {
+		entry = entry.WithField("client", identity)
+	}
+
+	if classified, ok := err.(*classifiedError); ok {
+		entry = entry.WithError(classified.cause)
+	}
+
+	if serverFault(code) {
+		entry.Error("call failed")
+	} else {
+		entry.Info("call finished")
+	}
+}
+
+// loggingInterceptor logs every call, it must run inside tracing and identity interceptors to log their results
+func (s *Server) loggingInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	res, err := handler(ctx, req)
+
+	var peerAddr string
+	if p, ok := peer.FromContext(ctx); ok {
+		peerAddr = p.Addr.String()
+	}
+
+	logCall(ctx, s.conf.Logger, info.FullMethod, peerAddr, start, err)
+	return res, err
+}
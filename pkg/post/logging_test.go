@@ -0,0 +1,104 @@
+package post
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// jaegerSpan has span context printed like Jaeger one
+type jaegerSpan struct {
+	opentracing.Span
+}
+
+type jaegerSpanContext struct {
+	opentracing.SpanContext
+}
+
+func (jaegerSpanContext) String() string {
+	return "4bf92f3577b34da6:a3ce929d0e0e4736:0:1"
+}
+
+func (s jaegerSpan) Context() opentracing.SpanContext {
+	return jaegerSpanContext{s.Span.Context()}
+}
+
+func TestLoggingInterceptor(t *testing.T) {
+	log, hook := logtest.NewNullLogger()
+	conf := testConfig
+	conf.Logger = log
+	s := &Server{db: &mockdb{}, conf: conf}
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}})
+	ctx = opentracing.ContextWithSpan(ctx, jaegerSpan{mocktracer.New().StartSpan("GetPost")})
+	info := &grpc.UnaryServerInfo{FullMethod: "/post.Post/GetPost"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, errorStatus(errDummy)
+	}
+
+	_, err := s.loggingInterceptor(ctx, nil, info, handler)
+	if st := status.Convert(err); st.Code() != codes.Internal || strings.Contains(st.Message(), errDummy.Error()) {
+		t.Errorf("client must get sanitized status: got %v", st)
+	}
+
+	entry := hook.LastEntry()
+	if entry == nil || entry.Level != logrus.ErrorLevel {
+		t.Fatalf("failed call must be logged as error: got %v", entry)
+	}
+
+	want := logrus.Fields{
+		"method":   "/post.Post/GetPost",
+		"peer":     "10.0.0.1:5000",
+		"code":     "Internal",
+		"trace_id": "4bf92f3577b34da6",
+		"error":    errDummy,
+	}
+	for key, value := range want {
+		if entry.Data[key] != value {
+			t.Errorf("field %s: got %v want %v", key, entry.Data[key], value)
+		}
+	}
+
+	hook.Reset()
+	handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, statusNotFound
+	}
+
+	s.loggingInterceptor(context.Background(), nil, info, handler)
+	entry = hook.LastEntry()
+	if entry == nil || entry.Level != logrus.InfoLevel || entry.Data["code"] != "NotFound" || entry.Data["error"] != nil {
+		t.Errorf("client error must be logged as info without cause: got %v", entry)
+	}
+}
+
+func TestGatewayLogging(t *testing.T) {
+	log, hook := logtest.NewNullLogger()
+	conf := testConfig
+	conf.Logger = log
+	s := &Server{db: &mockdb{}, conf: conf}
+	h := newGateway(s, opentracing.NoopTracer{}, s.callInterceptor())
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/posts/"+dummyUID.String(), nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), errDummy.Error()) {
+		t.Errorf("client must get sanitized error: got %d %s", w.Code, w.Body)
+	}
+
+	entry := hook.LastEntry()
+	if entry == nil || entry.Data["method"] != "/post.Post/GetPost" || entry.Data["peer"] != req.RemoteAddr || entry.Data["error"] != errDummy {
+		t.Errorf("request must be logged with cause: got %v", entry)
+	}
+}
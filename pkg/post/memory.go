@@ -0,0 +1,541 @@
+package post
+
+import (
+	"bytes"
+	"html"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"golang.org/x/net/context"
+)
+
+// userKey identifies idempotency key of user
+type userKey struct {
+	userUID uuid.UUID
+	key     string
+}
+
+// memoryKey points to post created with idempotency key
+type memoryKey struct {
+	postUID   uuid.UUID
+	createdAt time.Time
+}
+
+// memoryDB is datastore keeping everything in memory, for development without Postgres.
+// It behaves like db except that search doesn't stem words. Calls never block on I/O, so contexts are ignored
+type memoryDB struct {
+	mu    sync.RWMutex
+	posts map[uuid.UUID]*Post
+	// votes maps post UID to votes by user UID
+	votes map[uuid.UUID]map[uuid.UUID]int
+	// revisions are ordered by number
+	revisions map[uuid.UUID][]*Revision
+	keys      map[userKey]memoryKey
+}
+
+func newMemoryDB() *memoryDB {
+	return &memoryDB{
+		posts:     make(map[uuid.UUID]*Post),
+		votes:     make(map[uuid.UUID]map[uuid.UUID]int),
+		revisions: make(map[uuid.UUID][]*Revision),
+		keys:      make(map[userKey]memoryKey),
+	}
+}
+
+// copyPost returns copy of p, body is left empty if omitBody is set
+func copyPost(p *Post, omitBody bool) *Post {
+	post := *p
+	if omitBody {
+		post.Body = ""
+	}
+
+	return &post
+}
+
+// livePost returns post unless it's missing or deleted
+func (db *memoryDB) livePost(uid uuid.UUID) (*Post, bool) {
+	post, ok := db.posts[uid]
+	if !ok || !post.DeletedAt.IsZero() {
+		return nil, false
+	}
+
+	return post, true
+}
+
+// comparePosts orders posts like ORDER BY rank DESC, created_at DESC, uid DESC, negative result means a goes first
+func comparePosts(a, b *pageCursor) int {
+	switch {
+	case a.Rank > b.Rank:
+		return -1
+	case a.Rank < b.Rank:
+		return 1
+	case a.CreatedAt.After(b.CreatedAt):
+		return -1
+	case a.CreatedAt.Before(b.CreatedAt):
+		return 1
+	default:
+		return -bytes.Compare(a.UID[:], b.UID[:])
+	}
+}
+
+func (db *memoryDB) getAllPosts(ctx context.Context, q listQuery) ([]*Post, error) {
+	return db.listPosts(func(p *Post) bool { return true }, q)
+}
+
+func (db *memoryDB) getAllPostsByCategory(ctx context.Context, categoryUID uuid.UUID, q listQuery) ([]*Post, error) {
+	return db.listPosts(func(p *Post) bool { return p.CategoryUID == categoryUID }, q)
+}
+
+// listPosts returns a page of not deleted posts matching filter in sort order
+func (db *memoryDB) listPosts(filter func(*Post) bool, q listQuery) ([]*Post, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	posts := make([]*Post, 0)
+	for _, p := range db.posts {
+		if !p.DeletedAt.IsZero() || !filter(p) || p.CreatedAt.Before(q.since) {
+			continue
+		}
+
+		if q.cursor != nil && comparePosts(cursorAfter(p, q.sort), q.cursor) <= 0 {
+			continue
+		}
+
+		posts = append(posts, p)
+	}
+
+	sort.Slice(posts, func(i, j int) bool {
+		return comparePosts(cursorAfter(posts[i], q.sort), cursorAfter(posts[j], q.sort)) < 0
+	})
+
+	pageNumber := q.pageNumber
+	if q.cursor != nil {
+		pageNumber = 0
+	}
+
+	start, end := pageBounds(len(posts), q.pageSize, pageNumber)
+	posts = posts[start:end]
+
+	result := make([]*Post, len(posts))
+	for i, p := range posts {
+		result[i] = copyPost(p, q.omitBody)
+	}
+
+	return result, nil
+}
+
+// pageBounds returns bounds of page number pageNumber of pageSize items in list of n items
+func pageBounds(n int, pageSize, pageNumber int32) (int, int) {
+	start := int(pageSize) * int(pageNumber)
+	if start > n {
+		start = n
+	}
+
+	end := start + int(pageSize)
+	if end > n {
+		end = n
+	}
+
+	return start, end
+}
+
+func (db *memoryDB) getOnePost(ctx context.Context, uid uuid.UUID, includeDeleted bool) (*Post, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	post, ok := db.posts[uid]
+	if !ok || !includeDeleted && !post.DeletedAt.IsZero() {
+		return nil, errNotFound
+	}
+
+	return copyPost(post, false), nil
+}
+
+// saveRevision copies current contents of post to its revisions
+func (db *memoryDB) saveRevision(p *Post) {
+	revision := &Revision{PostUID: p.UID, Number: p.Revision, Title: p.Title, URL: p.URL, Body: p.Body, CreatedAt: p.ModifiedAt}
+	db.revisions[p.UID] = append(db.revisions[p.UID], revision)
+}
+
+func (db *memoryDB) createPost(ctx context.Context, p *Post, idempotencyKey string, keyTTL time.Duration) (*Post, bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	now := time.Now()
+	id := userKey{p.UserUID, idempotencyKey}
+	if idempotencyKey != "" {
+		// expired key can be reused
+		if key, ok := db.keys[id]; ok && !key.createdAt.Before(now.Add(-keyTTL)) {
+			return copyPost(db.posts[key.postUID], false), false, nil
+		}
+	}
+
+	post := &Post{
+		UID:         uuid.New(),
+		UserUID:     p.UserUID,
+		CategoryUID: p.CategoryUID,
+		Title:       p.Title,
+		URL:         p.URL,
+		Body:        p.Body,
+		Kind:        p.Kind,
+		CreatedAt:   now,
+		ModifiedAt:  now,
+		HotRank:     hotRank(0, now),
+		Revision:    1,
+		Version:     1,
+	}
+
+	db.posts[post.UID] = post
+	db.saveRevision(post)
+	if idempotencyKey != "" {
+		db.keys[id] = memoryKey{post.UID, now}
+	}
+
+	return copyPost(post, false), true, nil
+}
+
+func (db *memoryDB) purgeIdempotencyKeys(ctx context.Context, t time.Time) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var n int64
+	for id, key := range db.keys {
+		if key.createdAt.Before(t) {
+			delete(db.keys, id)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// conditionalPost returns not deleted post if its version is expectedVersion, zero expectedVersion matches any
+func (db *memoryDB) conditionalPost(uid uuid.UUID, expectedVersion int64) (*Post, error) {
+	post, ok := db.livePost(uid)
+	if !ok {
+		return nil, errNotFound
+	}
+
+	if expectedVersion != 0 && post.Version != expectedVersion {
+		return nil, errVersionMismatch
+	}
+
+	return post, nil
+}
+
+func (db *memoryDB) updatePost(ctx context.Context, uid uuid.UUID, u postUpdate, expectedVersion int64) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	post, err := db.conditionalPost(uid, expectedVersion)
+	if err != nil {
+		return 0, err
+	}
+
+	if u.empty() {
+		return post.Version, nil
+	}
+
+	if u.title != nil {
+		post.Title = *u.title
+	}
+
+	if u.url != nil {
+		post.URL = *u.url
+	}
+
+	if u.body != nil {
+		post.Body = *u.body
+	}
+
+	post.ModifiedAt = time.Now()
+	post.Revision++
+	post.Version++
+	db.saveRevision(post)
+	return post.Version, nil
+}
+
+func (db *memoryDB) deletePost(ctx context.Context, uid uuid.UUID, expectedVersion int64) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	post, err := db.conditionalPost(uid, expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	post.DeletedAt = time.Now()
+	post.Version++
+	return nil
+}
+
+func (db *memoryDB) restorePost(ctx context.Context, uid uuid.UUID) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	post, ok := db.posts[uid]
+	if !ok || post.DeletedAt.IsZero() {
+		return errNotFound
+	}
+
+	post.DeletedAt = time.Time{}
+	post.Version++
+	return nil
+}
+
+// remove deletes post with everything referencing it
+func (db *memoryDB) remove(uid uuid.UUID) {
+	delete(db.posts, uid)
+	delete(db.votes, uid)
+	delete(db.revisions, uid)
+	for id, key := range db.keys {
+		if key.postUID == uid {
+			delete(db.keys, id)
+		}
+	}
+}
+
+func (db *memoryDB) purgePost(ctx context.Context, uid uuid.UUID) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.posts[uid]; !ok {
+		return errNotFound
+	}
+
+	db.remove(uid)
+	return nil
+}
+
+func (db *memoryDB) purgeDeleted(ctx context.Context, t time.Time) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var n int64
+	for uid, post := range db.posts {
+		if !post.DeletedAt.IsZero() && post.DeletedAt.Before(t) {
+			db.remove(uid)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+func (db *memoryDB) checkPostExists(ctx context.Context, uid uuid.UUID) (bool, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	_, ok := db.livePost(uid)
+	return ok, nil
+}
+
+func (db *memoryDB) getPostOwner(ctx context.Context, uid uuid.UUID) (string, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	post, ok := db.posts[uid]
+	if !ok {
+		return "", errNotFound
+	}
+
+	return post.UserUID.String(), nil
+}
+
+func (db *memoryDB) vote(ctx context.Context, postUID, userUID uuid.UUID, value int) (int32, int32, error) {
+	return db.setVote(postUID, userUID, value)
+}
+
+func (db *memoryDB) removeVote(ctx context.Context, postUID, userUID uuid.UUID) (int32, int32, error) {
+	return db.setVote(postUID, userUID, 0)
+}
+
+// setVote stores user's vote (0 removes it) and recounts post votes
+func (db *memoryDB) setVote(postUID, userUID uuid.UUID, value int) (int32, int32, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	post, ok := db.livePost(postUID)
+	if !ok {
+		return 0, 0, errNotFound
+	}
+
+	votes := db.votes[postUID]
+	if votes == nil {
+		votes = make(map[uuid.UUID]int)
+		db.votes[postUID] = votes
+	}
+
+	if value == 0 {
+		delete(votes, userUID)
+	} else {
+		votes[userUID] = value
+	}
+
+	var upvotes, downvotes int32
+	for _, v := range votes {
+		if v > 0 {
+			upvotes++
+		} else {
+			downvotes++
+		}
+	}
+
+	post.Upvotes = upvotes
+	post.Downvotes = downvotes
+	post.HotRank = hotRank(post.Score(), post.CreatedAt)
+	post.Controversy = controversy(upvotes, downvotes)
+	return upvotes, downvotes, nil
+}
+
+// stopWords are ignored in search queries like Postgres english configuration does
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "but": true,
+	"by": true, "for": true, "if": true, "in": true, "into": true, "is": true, "it": true, "no": true,
+	"not": true, "of": true, "on": true, "or": true, "such": true, "that": true, "the": true, "their": true,
+	"then": true, "there": true, "these": true, "they": true, "this": true, "to": true, "was": true,
+	"will": true, "with": true,
+}
+
+// splitWords returns lower case words of s
+func splitWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// highlight escapes s as HTML and wraps its words found in terms in <b></b>
+func highlight(s string, terms map[string]bool) string {
+	var b strings.Builder
+	word := make([]rune, 0)
+	flush := func() {
+		if terms[strings.ToLower(string(word))] {
+			b.WriteString("<b>" + html.EscapeString(string(word)) + "</b>")
+		} else {
+			b.WriteString(html.EscapeString(string(word)))
+		}
+
+		word = word[:0]
+	}
+
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			word = append(word, r)
+			continue
+		}
+
+		flush()
+		b.WriteString(html.EscapeString(string(r)))
+	}
+
+	flush()
+	return b.String()
+}
+
+func (db *memoryDB) searchPosts(ctx context.Context, text string, categoryUID, userUID uuid.UUID, pageSize, pageNumber int32) ([]*SearchResult, error) {
+	terms := make(map[string]bool)
+	for _, w := range splitWords(text) {
+		if !stopWords[w] {
+			terms[w] = true
+		}
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	results := make([]*SearchResult, 0)
+	if len(terms) == 0 {
+		return results, nil
+	}
+
+	for _, p := range db.posts {
+		if !p.DeletedAt.IsZero() || categoryUID != uuid.Nil && p.CategoryUID != categoryUID || userUID != uuid.Nil && p.UserUID != userUID {
+			continue
+		}
+
+		words := append(splitWords(p.Title), splitWords(p.URL)...)
+		found := make(map[string]bool)
+		var matches int
+		for _, w := range words {
+			if terms[w] {
+				found[w] = true
+				matches++
+			}
+		}
+
+		// every query term must match
+		if len(found) < len(terms) {
+			continue
+		}
+
+		results = append(results, &SearchResult{
+			Post:           copyPost(p, false),
+			Relevance:      float32(matches) / float32(len(words)),
+			TitleHighlight: highlight(p.Title, terms),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a := &pageCursor{Rank: float64(results[i].Relevance), CreatedAt: results[i].Post.CreatedAt, UID: results[i].Post.UID}
+		b := &pageCursor{Rank: float64(results[j].Relevance), CreatedAt: results[j].Post.CreatedAt, UID: results[j].Post.UID}
+		return comparePosts(a, b) < 0
+	})
+
+	start, end := pageBounds(len(results), pageSize, pageNumber)
+	return results[start:end], nil
+}
+
+// listRevisions returns revisions of not deleted post, latest first
+func (db *memoryDB) listRevisions(ctx context.Context, uid uuid.UUID, pageSize, pageNumber int32) ([]*Revision, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if _, ok := db.livePost(uid); !ok {
+		return nil, errNotFound
+	}
+
+	revisions := db.revisions[uid]
+	latestFirst := make([]*Revision, len(revisions))
+	for i, r := range revisions {
+		revision := *r
+		latestFirst[len(revisions)-1-i] = &revision
+	}
+
+	start, end := pageBounds(len(latestFirst), pageSize, pageNumber)
+	return latestFirst[start:end], nil
+}
+
+// getRevision returns revision of not deleted post by its number
+func (db *memoryDB) getRevision(ctx context.Context, uid uuid.UUID, number int32) (*Revision, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if _, ok := db.livePost(uid); !ok {
+		return nil, errNotFound
+	}
+
+	for _, r := range db.revisions[uid] {
+		if r.Number == number {
+			revision := *r
+			return &revision, nil
+		}
+	}
+
+	return nil, errNotFound
+}
+
+func (db *memoryDB) ping(ctx context.Context) error {
+	return nil
+}
+
+func (db *memoryDB) checkSchema(ctx context.Context) error {
+	return nil
+}
+
+func (db *memoryDB) close() error {
+	return nil
+}
@@ -0,0 +1,282 @@
+package post
+
+import (
+	"database/sql"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const metricsNamespace = "post"
+
+var (
+	rpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "rpc_requests_total",
+		Help:      "Number of handled RPCs by method and status code.",
+	}, []string{"method", "code"})
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "rpc_duration_seconds",
+		Help:      "RPC latency by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+	dbDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Datastore method latency.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+	dbErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "db_errors_total",
+		Help:      "Number of failed datastore method calls.",
+	}, []string{"method"})
+	postsCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "posts_created_total",
+		Help:      "Number of created posts by category.",
+	}, []string{"category"})
+)
+
+func init() {
+	prometheus.MustRegister(rpcRequests, rpcDuration, dbDuration, dbErrors, postsCreated)
+}
+
+// metricsInterceptor counts RPCs and measures their latency
+func metricsInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	method := path.Base(info.FullMethod)
+	rpcDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	rpcRequests.WithLabelValues(method, status.Code(err).String()).Inc()
+	return resp, err
+}
+
+// chainInterceptors returns interceptor calling outer one with inner one as handler
+func chainInterceptors(outer, inner grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return outer(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return inner(ctx, req, info, handler)
+		})
+	}
+}
+
+// dbStatsCollector exports connection pool stats
+type dbStatsCollector struct {
+	db           *sql.DB
+	open         *prometheus.Desc
+	inUse        *prometheus.Desc
+	idle         *prometheus.Desc
+	waitCount    *prometheus.Desc
+	waitDuration *prometheus.Desc
+}
+
+func newDBStatsCollector(db *sql.DB) *dbStatsCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "db", name), help, nil, nil)
+	}
+
+	return &dbStatsCollector{
+		db:           db,
+		open:         desc("open_connections", "Number of established connections."),
+		inUse:        desc("in_use_connections", "Number of connections currently in use."),
+		idle:         desc("idle_connections", "Number of idle connections."),
+		waitCount:    desc("wait_count_total", "Number of times a connection was waited for."),
+		waitDuration: desc("wait_duration_seconds_total", "Total time spent waiting for a connection."),
+	}
+}
+
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.open
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitCount
+	ch <- c.waitDuration
+}
+
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.db.Stats()
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds())
+}
+
+// registerDBStats exports stats of connection pool, it replaces pool of server created earlier in the same process
+func registerDBStats(db *sql.DB) error {
+	collector := newDBStatsCollector(db)
+	err := prometheus.Register(collector)
+	if existing, ok := err.(prometheus.AlreadyRegisteredError); ok {
+		prometheus.Unregister(existing.ExistingCollector)
+		err = prometheus.Register(collector)
+	}
+
+	return err
+}
+
+// instrumentedDB measures latency and errors of wrapped datastore methods
+type instrumentedDB struct {
+	next datastore
+}
+
+// expectedError tells if datastore error is expected outcome like missing post or request canceled by client
+func expectedError(err error) bool {
+	switch err {
+	case nil, errNotFound, errVersionMismatch, context.Canceled:
+		return true
+	default:
+		return false
+	}
+}
+
+// observe records call of datastore method started at start, expected outcomes are not errors
+func observe(method string, start time.Time, err error) {
+	dbDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if !expectedError(err) {
+		dbErrors.WithLabelValues(method).Inc()
+	}
+}
+
+func (db instrumentedDB) getAllPosts(ctx context.Context, q listQuery) ([]*Post, error) {
+	start := time.Now()
+	posts, err := db.next.getAllPosts(ctx, q)
+	observe("getAllPosts", start, err)
+	return posts, err
+}
+
+func (db instrumentedDB) getAllPostsByCategory(ctx context.Context, categoryUID uuid.UUID, q listQuery) ([]*Post, error) {
+	start := time.Now()
+	posts, err := db.next.getAllPostsByCategory(ctx, categoryUID, q)
+	observe("getAllPostsByCategory", start, err)
+	return posts, err
+}
+
+func (db instrumentedDB) getOnePost(ctx context.Context, uid uuid.UUID, includeDeleted bool) (*Post, error) {
+	start := time.Now()
+	post, err := db.next.getOnePost(ctx, uid, includeDeleted)
+	observe("getOnePost", start, err)
+	return post, err
+}
+
+func (db instrumentedDB) createPost(ctx context.Context, p *Post, idempotencyKey string, keyTTL time.Duration) (*Post, bool, error) {
+	start := time.Now()
+	post, created, err := db.next.createPost(ctx, p, idempotencyKey, keyTTL)
+	observe("createPost", start, err)
+	return post, created, err
+}
+
+func (db instrumentedDB) updatePost(ctx context.Context, uid uuid.UUID, u postUpdate, expectedVersion int64) (int64, error) {
+	start := time.Now()
+	version, err := db.next.updatePost(ctx, uid, u, expectedVersion)
+	observe("updatePost", start, err)
+	return version, err
+}
+
+func (db instrumentedDB) deletePost(ctx context.Context, uid uuid.UUID, expectedVersion int64) error {
+	start := time.Now()
+	err := db.next.deletePost(ctx, uid, expectedVersion)
+	observe("deletePost", start, err)
+	return err
+}
+
+func (db instrumentedDB) restorePost(ctx context.Context, uid uuid.UUID) error {
+	start := time.Now()
+	err := db.next.restorePost(ctx, uid)
+	observe("restorePost", start, err)
+	return err
+}
+
+func (db instrumentedDB) purgePost(ctx context.Context, uid uuid.UUID) error {
+	start := time.Now()
+	err := db.next.purgePost(ctx, uid)
+	observe("purgePost", start, err)
+	return err
+}
+
+func (db instrumentedDB) purgeDeleted(ctx context.Context, t time.Time) (int64, error) {
+	start := time.Now()
+	n, err := db.next.purgeDeleted(ctx, t)
+	observe("purgeDeleted", start, err)
+	return n, err
+}
+
+func (db instrumentedDB) purgeIdempotencyKeys(ctx context.Context, t time.Time) (int64, error) {
+	start := time.Now()
+	n, err := db.next.purgeIdempotencyKeys(ctx, t)
+	observe("purgeIdempotencyKeys", start, err)
+	return n, err
+}
+
+func (db instrumentedDB) listRevisions(ctx context.Context, uid uuid.UUID, pageSize, pageNumber int32) ([]*Revision, error) {
+	start := time.Now()
+	revisions, err := db.next.listRevisions(ctx, uid, pageSize, pageNumber)
+	observe("listRevisions", start, err)
+	return revisions, err
+}
+
+func (db instrumentedDB) getRevision(ctx context.Context, uid uuid.UUID, number int32) (*Revision, error) {
+	start := time.Now()
+	revision, err := db.next.getRevision(ctx, uid, number)
+	observe("getRevision", start, err)
+	return revision, err
+}
+
+func (db instrumentedDB) checkPostExists(ctx context.Context, uid uuid.UUID) (bool, error) {
+	start := time.Now()
+	exists, err := db.next.checkPostExists(ctx, uid)
+	observe("checkPostExists", start, err)
+	return exists, err
+}
+
+func (db instrumentedDB) getPostOwner(ctx context.Context, uid uuid.UUID) (string, error) {
+	start := time.Now()
+	owner, err := db.next.getPostOwner(ctx, uid)
+	observe("getPostOwner", start, err)
+	return owner, err
+}
+
+func (db instrumentedDB) vote(ctx context.Context, postUID, userUID uuid.UUID, value int) (int32, int32, error) {
+	start := time.Now()
+	upvotes, downvotes, err := db.next.vote(ctx, postUID, userUID, value)
+	observe("vote", start, err)
+	return upvotes, downvotes, err
+}
+
+func (db instrumentedDB) removeVote(ctx context.Context, postUID, userUID uuid.UUID) (int32, int32, error) {
+	start := time.Now()
+	upvotes, downvotes, err := db.next.removeVote(ctx, postUID, userUID)
+	observe("removeVote", start, err)
+	return upvotes, downvotes, err
+}
+
+func (db instrumentedDB) searchPosts(ctx context.Context, query string, categoryUID, userUID uuid.UUID, pageSize, pageNumber int32) ([]*SearchResult, error) {
+	start := time.Now()
+	results, err := db.next.searchPosts(ctx, query, categoryUID, userUID, pageSize, pageNumber)
+	observe("searchPosts", start, err)
+	return results, err
+}
+
+func (db instrumentedDB) ping(ctx context.Context) error {
+	start := time.Now()
+	err := db.next.ping(ctx)
+	observe("ping", start, err)
+	return err
+}
+
+func (db instrumentedDB) close() error {
+	return db.next.close()
+}
+
+func (db instrumentedDB) checkSchema(ctx context.Context) error {
+	start := time.Now()
+	err := db.next.checkSchema(ctx)
+	observe("checkSchema", start, err)
+	return err
+}
@@ -0,0 +1,85 @@
+package post
+
+import (
+	"testing"
+
+	pb "github.com/andreymgn/RSOI-post/pkg/post/proto"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+func TestInstrumentedDB(t *testing.T) {
+	ctx := context.Background()
+	db := instrumentedDB{&mockdb{}}
+	before := testutil.ToFloat64(dbErrors.WithLabelValues("checkPostExists"))
+	if _, err := db.checkPostExists(ctx, uuid.Nil); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if _, err := db.checkPostExists(ctx, dummyUID); err != errDummy {
+		t.Errorf("unexpected error: got %v want %v", err, errDummy)
+	}
+
+	if got := testutil.ToFloat64(dbErrors.WithLabelValues("checkPostExists")) - before; got != 1 {
+		t.Errorf("unexpected number of errors: got %v want 1", got)
+	}
+}
+
+func TestMetricsInterceptor(t *testing.T) {
+	var calls []string
+	interceptor := func(name string) grpc.UnaryServerInterceptor {
+		return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			calls = append(calls, name)
+			return handler(ctx, req)
+		}
+	}
+
+	chain := chainInterceptors(interceptor("outer"), chainInterceptors(metricsInterceptor, interceptor("inner")))
+	info := &grpc.UnaryServerInfo{FullMethod: "/post.Post/GetPost"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, statusNotFound
+	}
+
+	before := testutil.ToFloat64(rpcRequests.WithLabelValues("GetPost", "NotFound"))
+	if _, err := chain(context.Background(), nil, info, handler); err != statusNotFound {
+		t.Errorf("unexpected error: got %v want %v", err, statusNotFound)
+	}
+
+	if len(calls) != 2 || calls[0] != "outer" || calls[1] != "inner" {
+		t.Errorf("unexpected interceptor calls %v", calls)
+	}
+
+	if got := testutil.ToFloat64(rpcRequests.WithLabelValues("GetPost", "NotFound")) - before; got != 1 {
+		t.Errorf("unexpected number of requests: got %v want 1", got)
+	}
+}
+
+func TestPostsCreatedMetric(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	category := dummyUID.String()
+	before := testutil.ToFloat64(postsCreated.WithLabelValues(category))
+	req := &pb.CreatePostRequest{Title: "success", UserUid: nilUIDString, CategoryUid: category, IdempotencyKey: "metrics"}
+	for i := 0; i < 2; i++ {
+		if _, err := s.CreatePost(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+
+	if got := testutil.ToFloat64(postsCreated.WithLabelValues(category)) - before; got != 1 {
+		t.Errorf("unexpected number of created posts: got %v want 1", got)
+	}
+}
+
+func TestNewServerTwice(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		// connection isn't opened until first query
+		s, err := NewServer("postgres://localhost/posts?sslmode=disable", Config{})
+		if err != nil {
+			t.Fatalf("server %d: unexpected error %v", i, err)
+		}
+
+		s.db.close()
+	}
+}
@@ -0,0 +1,261 @@
+package post
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// migrationLockKey is Postgres advisory lock key held while migrating, so replicas don't migrate concurrently
+const migrationLockKey = 0x706f7374
+
+const createMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    applied_at TIMESTAMP WITH TIME ZONE NOT NULL
+)`
+
+// migration changes schema from version-1 to version, down reverts it
+type migration struct {
+	version int
+	name    string
+	up      string
+	down    string
+}
+
+// MigrationState describes migration and when it was applied
+type MigrationState struct {
+	Version int
+	Name    string
+	// AppliedAt is zero for pending migration
+	AppliedAt time.Time
+}
+
+// latestVersion returns version of the last known migration
+func latestVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// withMigrationLock calls fn on connection holding migration lock
+func withMigrationLock(db *sql.DB, fn func(*sql.Conn) error) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	// advisory lock belongs to session, so everything is done on the same connection
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		return err
+	}
+
+	defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrationLockKey)
+
+	if _, err := conn.ExecContext(ctx, createMigrationsTable); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// appliedMigrations returns application time of applied migrations by version
+func appliedMigrations(q queryer) (map[int]time.Time, error) {
+	rows, err := q.QueryContext(context.Background(), "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	result := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+
+		result[version] = appliedAt
+	}
+
+	return result, rows.Err()
+}
+
+// pendingMigrations returns migrations not listed in applied, in order they should be applied
+func pendingMigrations(applied map[int]time.Time) []migration {
+	var result []migration
+	for _, m := range migrations {
+		if _, ok := applied[m.version]; !ok {
+			result = append(result, m)
+		}
+	}
+
+	return result
+}
+
+// runMigration executes query and records migration change in one transaction
+func runMigration(conn *sql.Conn, query, record string, args ...interface{}) error {
+	ctx := context.Background()
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// adoptBaseline records migration 1 as applied to database created from the initial schema by hand,
+// so migrating it starts with migration 2
+func adoptBaseline(conn *sql.Conn, applied map[int]time.Time) error {
+	if len(applied) != 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := conn.QueryRowContext(context.Background(), "SELECT to_regclass('posts') IS NOT NULL").Scan(&exists); err != nil {
+		return err
+	}
+
+	if !exists {
+		return nil
+	}
+
+	m := migrations[0]
+	now := time.Now()
+	record := "INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)"
+	if _, err := conn.ExecContext(context.Background(), record, m.version, m.name, now); err != nil {
+		return err
+	}
+
+	applied[m.version] = now
+	return nil
+}
+
+// migrateUp applies pending migrations and returns their number
+func migrateUp(db *sql.DB) (int, error) {
+	var n int
+	err := withMigrationLock(db, func(conn *sql.Conn) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		if err := adoptBaseline(conn, applied); err != nil {
+			return err
+		}
+
+		for _, m := range pendingMigrations(applied) {
+			record := "INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)"
+			if err := runMigration(conn, m.up, record, m.version, m.name, time.Now()); err != nil {
+				return fmt.Errorf("migration %d (%s) failed: %v", m.version, m.name, err)
+			}
+
+			n++
+		}
+
+		return nil
+	})
+
+	return n, err
+}
+
+// migrateDown reverts the last applied migration and returns its version, zero if nothing was applied
+func migrateDown(db *sql.DB) (int, error) {
+	var version int
+	err := withMigrationLock(db, func(conn *sql.Conn) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0; i-- {
+			m := migrations[i]
+			if _, ok := applied[m.version]; !ok {
+				continue
+			}
+
+			record := "DELETE FROM schema_migrations WHERE version=$1"
+			if err := runMigration(conn, m.down, record, m.version); err != nil {
+				return fmt.Errorf("reverting migration %d (%s) failed: %v", m.version, m.name, err)
+			}
+
+			version = m.version
+			return nil
+		}
+
+		return nil
+	})
+
+	return version, err
+}
+
+// migrationStatus returns state of every known migration.
+// It only reads, so it neither waits for running migration nor creates migrations table
+func migrationStatus(db *sql.DB) ([]MigrationState, error) {
+	var exists bool
+	if err := db.QueryRow("SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]time.Time)
+	if exists {
+		var err error
+		if applied, err = appliedMigrations(db); err != nil {
+			return nil, err
+		}
+	}
+
+	var result []MigrationState
+	for _, m := range migrations {
+		result = append(result, MigrationState{m.version, m.name, applied[m.version]})
+	}
+
+	return result, nil
+}
+
+// MigrateUp applies pending migrations to database and returns their number
+func MigrateUp(connString string) (int, error) {
+	db, err := sql.Open("postgres", connString)
+	if err != nil {
+		return 0, err
+	}
+
+	defer db.Close()
+	return migrateUp(db)
+}
+
+// MigrateDown reverts the last applied migration and returns its version, zero if nothing was applied
+func MigrateDown(connString string) (int, error) {
+	db, err := sql.Open("postgres", connString)
+	if err != nil {
+		return 0, err
+	}
+
+	defer db.Close()
+	return migrateDown(db)
+}
+
+// MigrationStatus returns state of every migration known to this build
+func MigrationStatus(connString string) ([]MigrationState, error) {
+	db, err := sql.Open("postgres", connString)
+	if err != nil {
+		return nil, err
+	}
+
+	defer db.Close()
+	return migrationStatus(db)
+}
@@ -0,0 +1,117 @@
+package post
+
+import (
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/net/context"
+)
+
+func TestMigrationsOrdered(t *testing.T) {
+	for i, m := range migrations {
+		if m.version != i+1 {
+			t.Errorf("migration %q: got version %d want %d", m.name, m.version, i+1)
+		}
+
+		if m.up == "" || m.down == "" {
+			t.Errorf("migration %d must have both up and down queries", m.version)
+		}
+	}
+}
+
+func TestPendingMigrations(t *testing.T) {
+	if pending := pendingMigrations(nil); len(pending) != len(migrations) {
+		t.Errorf("all migrations must be pending on empty database: got %d want %d", len(pending), len(migrations))
+	}
+
+	applied := make(map[int]time.Time)
+	for _, m := range migrations {
+		applied[m.version] = time.Now()
+	}
+
+	if pending := pendingMigrations(applied); len(pending) != 0 {
+		t.Errorf("unexpected pending migrations %v", pending)
+	}
+}
+
+// TestPostgresMigrations upgrades database created from the initial schema by hand to the latest version,
+// then reverts and applies all migrations again. All tables in database from TEST_POSTGRES_CONN are dropped
+func TestPostgresMigrations(t *testing.T) {
+	connString := os.Getenv("TEST_POSTGRES_CONN")
+	if connString == "" {
+		t.Skip("TEST_POSTGRES_CONN is not set")
+	}
+
+	postgres, err := newDB(connString)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer postgres.Close()
+	if _, err := postgres.Exec("DROP TABLE IF EXISTS schema_migrations, idempotency_keys, post_revisions, votes, posts"); err != nil {
+		t.Fatal(err)
+	}
+
+	states, err := migrationStatus(postgres.DB)
+	if err != nil || len(states) != len(migrations) || !states[0].AppliedAt.IsZero() {
+		t.Fatalf("all migrations must be pending without migrations table: got %v %v", states, err)
+	}
+
+	var exists bool
+	if err := postgres.QueryRow("SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil || exists {
+		t.Fatalf("status must not create migrations table: got %v %v", exists, err)
+	}
+
+	if _, err := postgres.Exec(migrations[0].up); err != nil {
+		t.Fatal(err)
+	}
+
+	uid, now := uuid.New(), time.Now()
+	query := "INSERT INTO posts (uid, user_uid, category_uid, title, url, created_at, modified_at) VALUES ($1, $2, $3, $4, $5, $6, $7)"
+	if _, err := postgres.Exec(query, uid.String(), dummyUID.String(), uuid.Nil.String(), "gopher news", "https://example.com", now, now); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := migrateUp(postgres.DB); err != nil || n != len(migrations)-1 {
+		t.Fatalf("unexpected result of migrating baseline database %v %v", n, err)
+	}
+
+	if states, err := migrationStatus(postgres.DB); err != nil || states[len(states)-1].AppliedAt.IsZero() {
+		t.Errorf("all migrations must be applied: got %v %v", states, err)
+	}
+
+	ctx := context.Background()
+	if err := postgres.checkSchema(ctx); err != nil {
+		t.Errorf("schema must be up to date: got %v", err)
+	}
+
+	p, err := postgres.getOnePost(ctx, uid, false)
+	if err != nil || p.Title != "gopher news" || p.Kind != LinkPost || p.Revision != 1 || p.Version != 1 {
+		t.Fatalf("unexpected post %+v %v", p, err)
+	}
+
+	if math.Abs(p.HotRank-hotRank(0, now)) > 1e-9 {
+		t.Errorf("unexpected hot rank: got %v want %v", p.HotRank, hotRank(0, now))
+	}
+
+	if r, err := postgres.getRevision(ctx, uid, 1); err != nil || r.Title != p.Title {
+		t.Errorf("current contents must be the first revision: got %+v %v", r, err)
+	}
+
+	if results, err := postgres.searchPosts(ctx, "gopher", uuid.Nil, uuid.Nil, 10, 0); err != nil || len(results) != 1 {
+		t.Errorf("existing post must be searchable: got %v %v", results, err)
+	}
+
+	for version := latestVersion(); version > 0; version-- {
+		if got, err := migrateDown(postgres.DB); err != nil || got != version {
+			t.Fatalf("unexpected result of reverting migration %d: %v %v", version, got, err)
+		}
+	}
+
+	if n, err := migrateUp(postgres.DB); err != nil || n != len(migrations) {
+		t.Errorf("unexpected result of migrating empty database %v %v", n, err)
+	}
+}
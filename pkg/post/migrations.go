@@ -0,0 +1,218 @@
+package post
+
+// migrations are applied in order of versions, schema_migrations table keeps applied ones.
+// Applied migration must never be changed, schema changes go to new migration.
+// Migration 1 is the schema databases were created with by hand before migrations, see adoptBaseline
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "initial schema",
+		up: `
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE posts (
+    uid UUID PRIMARY KEY,
+    user_uid UUID NOT NULL,
+    category_uid UUID NOT NULL,
+    title VARCHAR(80) NOT NULL,
+    url VARCHAR(80),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    modified_at TIMESTAMP WITH TIME ZONE NOT NULL
+);`,
+		down: `DROP TABLE posts;`,
+	},
+	{
+		version: 2,
+		name:    "listing indexes",
+		up: `
+CREATE INDEX posts_created_at_idx ON posts (created_at DESC, uid DESC);
+CREATE INDEX posts_category_created_at_idx ON posts (category_uid, created_at DESC, uid DESC);
+`,
+		down: `
+DROP INDEX posts_category_created_at_idx;
+DROP INDEX posts_created_at_idx;
+`,
+	},
+	{
+		version: 3,
+		name:    "votes",
+		up: `
+ALTER TABLE posts
+    ADD COLUMN upvotes INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN downvotes INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN score INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE votes (
+    post_uid UUID NOT NULL REFERENCES posts (uid) ON DELETE CASCADE,
+    user_uid UUID NOT NULL,
+    value SMALLINT NOT NULL CHECK (value IN (-1, 1)),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (post_uid, user_uid)
+);
+`,
+		down: `
+DROP TABLE votes;
+
+ALTER TABLE posts
+    DROP COLUMN score,
+    DROP COLUMN downvotes,
+    DROP COLUMN upvotes;
+`,
+	},
+	{
+		version: 4,
+		name:    "rank columns",
+		up: `
+ALTER TABLE posts
+    ADD COLUMN hot_rank DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN controversy DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+-- same as hotRank and controversy
+UPDATE posts SET
+    hot_rank = SIGN(score) * LOG(GREATEST(ABS(score), 1)) + (FLOOR(EXTRACT(EPOCH FROM created_at)) - 1514764800) / 45000,
+    controversy = CASE
+        WHEN upvotes > 0 AND downvotes > 0
+        THEN POWER(upvotes + downvotes, LEAST(upvotes, downvotes)::DOUBLE PRECISION / GREATEST(upvotes, downvotes))
+        ELSE 0
+    END;
+
+CREATE INDEX posts_hot_rank_idx ON posts (hot_rank DESC, created_at DESC, uid DESC);
+CREATE INDEX posts_category_hot_rank_idx ON posts (category_uid, hot_rank DESC, created_at DESC, uid DESC);
+CREATE INDEX posts_score_idx ON posts (score DESC, created_at DESC, uid DESC);
+CREATE INDEX posts_category_score_idx ON posts (category_uid, score DESC, created_at DESC, uid DESC);
+CREATE INDEX posts_controversy_idx ON posts (controversy DESC, created_at DESC, uid DESC);
+CREATE INDEX posts_category_controversy_idx ON posts (category_uid, controversy DESC, created_at DESC, uid DESC);
+`,
+		down: `
+DROP INDEX posts_category_score_idx;
+DROP INDEX posts_score_idx;
+
+ALTER TABLE posts
+    DROP COLUMN controversy,
+    DROP COLUMN hot_rank;
+`,
+	},
+	{
+		version: 5,
+		name:    "full-text search",
+		up: `
+ALTER TABLE posts ADD COLUMN search_vector TSVECTOR;
+
+CREATE TRIGGER posts_search_vector_update BEFORE INSERT OR UPDATE OF title, url ON posts
+    FOR EACH ROW EXECUTE PROCEDURE tsvector_update_trigger(search_vector, 'pg_catalog.english', title, url);
+
+-- fires the trigger for existing posts
+UPDATE posts SET title = title;
+
+CREATE INDEX posts_search_idx ON posts USING GIN (search_vector);
+`,
+		down: `
+DROP TRIGGER posts_search_vector_update ON posts;
+ALTER TABLE posts DROP COLUMN search_vector;
+`,
+	},
+	{
+		version: 6,
+		name:    "text posts",
+		up: `
+ALTER TABLE posts
+    ADD COLUMN body TEXT NOT NULL DEFAULT '',
+    ADD COLUMN kind SMALLINT NOT NULL DEFAULT 0,
+    ADD CONSTRAINT posts_kind_check CHECK (kind = 0 AND body = '' OR kind = 1 AND COALESCE(url, '') = '');
+`,
+		down: `
+ALTER TABLE posts
+    DROP COLUMN kind,
+    DROP COLUMN body;
+`,
+	},
+	{
+		version: 7,
+		name:    "soft delete",
+		up: `
+ALTER TABLE posts ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- listings skip deleted posts
+DROP INDEX posts_created_at_idx;
+DROP INDEX posts_category_created_at_idx;
+DROP INDEX posts_hot_rank_idx;
+DROP INDEX posts_category_hot_rank_idx;
+DROP INDEX posts_score_idx;
+DROP INDEX posts_category_score_idx;
+DROP INDEX posts_controversy_idx;
+DROP INDEX posts_category_controversy_idx;
+
+CREATE INDEX posts_created_at_idx ON posts (created_at DESC, uid DESC) WHERE deleted_at IS NULL;
+CREATE INDEX posts_category_created_at_idx ON posts (category_uid, created_at DESC, uid DESC) WHERE deleted_at IS NULL;
+CREATE INDEX posts_hot_rank_idx ON posts (hot_rank DESC, created_at DESC, uid DESC) WHERE deleted_at IS NULL;
+CREATE INDEX posts_category_hot_rank_idx ON posts (category_uid, hot_rank DESC, created_at DESC, uid DESC) WHERE deleted_at IS NULL;
+CREATE INDEX posts_score_idx ON posts (score DESC, created_at DESC, uid DESC) WHERE deleted_at IS NULL;
+CREATE INDEX posts_category_score_idx ON posts (category_uid, score DESC, created_at DESC, uid DESC) WHERE deleted_at IS NULL;
+CREATE INDEX posts_controversy_idx ON posts (controversy DESC, created_at DESC, uid DESC) WHERE deleted_at IS NULL;
+CREATE INDEX posts_category_controversy_idx ON posts (category_uid, controversy DESC, created_at DESC, uid DESC) WHERE deleted_at IS NULL;
+CREATE INDEX posts_deleted_at_idx ON posts (deleted_at) WHERE deleted_at IS NOT NULL;
+`,
+		down: `
+DELETE FROM posts WHERE deleted_at IS NOT NULL;
+
+-- dropping the column drops indexes using it
+ALTER TABLE posts DROP COLUMN deleted_at;
+
+CREATE INDEX posts_created_at_idx ON posts (created_at DESC, uid DESC);
+CREATE INDEX posts_category_created_at_idx ON posts (category_uid, created_at DESC, uid DESC);
+CREATE INDEX posts_hot_rank_idx ON posts (hot_rank DESC, created_at DESC, uid DESC);
+CREATE INDEX posts_category_hot_rank_idx ON posts (category_uid, hot_rank DESC, created_at DESC, uid DESC);
+CREATE INDEX posts_score_idx ON posts (score DESC, created_at DESC, uid DESC);
+CREATE INDEX posts_category_score_idx ON posts (category_uid, score DESC, created_at DESC, uid DESC);
+CREATE INDEX posts_controversy_idx ON posts (controversy DESC, created_at DESC, uid DESC);
+CREATE INDEX posts_category_controversy_idx ON posts (category_uid, controversy DESC, created_at DESC, uid DESC);
+`,
+	},
+	{
+		version: 8,
+		name:    "post revisions",
+		up: `
+ALTER TABLE posts ADD COLUMN revision INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE post_revisions (
+    post_uid UUID NOT NULL REFERENCES posts (uid) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    title VARCHAR(80) NOT NULL,
+    url VARCHAR(80),
+    body TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (post_uid, revision)
+);
+
+-- current contents of existing posts become their first revision, like saveRevision does on create
+INSERT INTO post_revisions (post_uid, revision, title, url, body, created_at)
+    SELECT uid, revision, title, url, body, modified_at FROM posts;
+`,
+		down: `
+DROP TABLE post_revisions;
+ALTER TABLE posts DROP COLUMN revision;
+`,
+	},
+	{
+		version: 9,
+		name:    "post versions",
+		up:      `ALTER TABLE posts ADD COLUMN version BIGINT NOT NULL DEFAULT 1;`,
+		down:    `ALTER TABLE posts DROP COLUMN version;`,
+	},
+	{
+		version: 10,
+		name:    "idempotency keys",
+		up: `
+CREATE TABLE idempotency_keys (
+    user_uid UUID NOT NULL,
+    key VARCHAR(128) NOT NULL,
+    post_uid UUID NOT NULL REFERENCES posts (uid) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (user_uid, key)
+);
+
+CREATE INDEX idempotency_keys_created_at_idx ON idempotency_keys (created_at);
+`,
+		down: `DROP TABLE idempotency_keys;`,
+	},
+}
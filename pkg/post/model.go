@@ -3,15 +3,29 @@ package post
 import (
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	"golang.org/x/net/context"
 )
 
 var (
-	errNotFound       = errors.New("post not found")
-	errPostNotCreated = errors.New("post not created")
+	errNotFound        = errors.New("post not found")
+	errPostNotCreated  = errors.New("post not created")
+	errVersionMismatch = errors.New("post version mismatch")
+)
+
+// PostKind tells if post links somewhere or has text body
+type PostKind int
+
+const (
+	// LinkPost has URL and no body
+	LinkPost PostKind = iota
+	// TextPost has body and no URL
+	TextPost
 )
 
 // Post describes a post
@@ -21,77 +35,260 @@ type Post struct {
 	CategoryUID uuid.UUID
 	Title       string
 	URL         string
+	Body        string
+	Kind        PostKind
 	CreatedAt   time.Time
 	ModifiedAt  time.Time
+	Upvotes     int32
+	Downvotes   int32
+	HotRank     float64
+	Controversy float64
+	// DeletedAt is zero unless post is deleted
+	DeletedAt time.Time
+	// Revision is number of current post revision, starting from 1
+	Revision int32
+	// Version changes on every update, deletion or restoration of post
+	Version int64
+}
+
+// Revision describes post contents after creation or update
+type Revision struct {
+	PostUID   uuid.UUID
+	Number    int32
+	Title     string
+	URL       string
+	Body      string
+	CreatedAt time.Time
+}
+
+// Score returns difference between upvotes and downvotes
+func (p *Post) Score() int32 {
+	return p.Upvotes - p.Downvotes
+}
+
+// SearchResult is a post matching search query
+type SearchResult struct {
+	Post      *Post
+	Relevance float32
+	// TitleHighlight is HTML-escaped post title with matching words wrapped in <b></b>
+	TitleHighlight string
+}
+
+// postUpdate holds new values of post fields, nil fields are left unchanged
+type postUpdate struct {
+	title *string
+	url   *string
+	body  *string
+}
+
+// empty checks if update changes no fields
+func (u postUpdate) empty() bool {
+	return u.title == nil && u.url == nil && u.body == nil
+}
+
+// listQuery describes a page of posts
+type listQuery struct {
+	pageSize   int32
+	pageNumber int32
+	// cursor is set when page was requested by token, pageNumber is ignored then
+	cursor *pageCursor
+	sort   sortOrder
+	// since limits list to posts created after it, unless zero
+	since time.Time
+	// omitBody makes listed posts have empty body
+	omitBody bool
 }
 
 type datastore interface {
-	getAllPosts(int32, int32) ([]*Post, error)
-	getAllPostsByCategory(uuid.UUID, int32, int32) ([]*Post, error)
-	getOnePost(uuid.UUID) (*Post, error)
-	createPost(string, string, uuid.UUID, uuid.UUID) (*Post, error)
-	updatePost(uuid.UUID, string, string) error
-	deletePost(uuid.UUID) error
-	checkPostExists(uuid.UUID) (bool, error)
-	getPostOwner(uuid.UUID) (string, error)
+	getAllPosts(context.Context, listQuery) ([]*Post, error)
+	getAllPostsByCategory(context.Context, uuid.UUID, listQuery) ([]*Post, error)
+	getOnePost(context.Context, uuid.UUID, bool) (*Post, error)
+	createPost(context.Context, *Post, string, time.Duration) (*Post, bool, error)
+	updatePost(context.Context, uuid.UUID, postUpdate, int64) (int64, error)
+	deletePost(context.Context, uuid.UUID, int64) error
+	restorePost(context.Context, uuid.UUID) error
+	purgePost(context.Context, uuid.UUID) error
+	purgeDeleted(context.Context, time.Time) (int64, error)
+	purgeIdempotencyKeys(context.Context, time.Time) (int64, error)
+	listRevisions(context.Context, uuid.UUID, int32, int32) ([]*Revision, error)
+	getRevision(context.Context, uuid.UUID, int32) (*Revision, error)
+	checkPostExists(context.Context, uuid.UUID) (bool, error)
+	getPostOwner(context.Context, uuid.UUID) (string, error)
+	vote(context.Context, uuid.UUID, uuid.UUID, int) (int32, int32, error)
+	removeVote(context.Context, uuid.UUID, uuid.UUID) (int32, int32, error)
+	searchPosts(context.Context, string, uuid.UUID, uuid.UUID, int32, int32) ([]*SearchResult, error)
+	ping(context.Context) error
+	checkSchema(context.Context) error
+	close() error
 }
 
 type db struct {
 	*sql.DB
+	// queryTimeout limits every datastore call unless context expires earlier, zero means no limit
+	queryTimeout time.Duration
 }
 
 func newDB(connString string) (*db, error) {
 	postgres, err := sql.Open("postgres", connString)
-	return &db{postgres}, err
+	return &db{DB: postgres}, err
 }
 
-func (db *db) getAllPosts(pageSize, pageNumber int32) ([]*Post, error) {
-	query := "SELECT uid, user_uid, category_uid, title, url, created_at, modified_at FROM posts ORDER BY created_at DESC LIMIT $1 OFFSET $2"
-	lastRecord := pageNumber * pageSize
-	rows, err := db.Query(query, pageSize, lastRecord)
-	if err != nil {
-		return nil, err
+// withTimeout limits ctx with query timeout. Returned function must be deferred with address of returned error,
+// it replaces error caused by expired or canceled context with context error, so callers can tell them apart
+func (db *db) withTimeout(ctx context.Context) (context.Context, func(*error)) {
+	var cancel context.CancelFunc
+	if db.queryTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, db.queryTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
 	}
 
-	defer rows.Close()
-	result := make([]*Post, 0)
-	for rows.Next() {
-		post := new(Post)
-		var uid, userUID, categoryUID string
-		err := rows.Scan(&uid, &userUID, &categoryUID, &post.Title, &post.URL, &post.CreatedAt, &post.ModifiedAt)
-		if err != nil {
-			return nil, err
+	return ctx, func(err *error) {
+		if *err != nil && ctx.Err() != nil {
+			*err = ctx.Err()
 		}
 
-		post.UID, err = uuid.Parse(uid)
-		if err != nil {
-			return nil, err
-		}
+		cancel()
+	}
+}
 
-		post.UserUID, err = uuid.Parse(userUID)
-		if err != nil {
-			return nil, err
-		}
+// start begins datastore call of method: ctx is limited with query timeout and call is traced as child of span in ctx.
+// Returned function must be deferred with address of returned error
+func (db *db) start(ctx context.Context, method string) (context.Context, func(*error)) {
+	ctx, finish := startDBSpan(ctx, method)
+	ctx, done := db.withTimeout(ctx)
+	return ctx, func(err *error) {
+		done(err)
+		finish(*err)
+	}
+}
 
-		post.CategoryUID, err = uuid.Parse(categoryUID)
-		if err != nil {
-			return nil, err
-		}
+// ping checks database connection
+func (db *db) ping(ctx context.Context) (err error) {
+	ctx, done := db.start(ctx, "ping")
+	defer done(&err)
+	return db.PingContext(ctx)
+}
 
-		result = append(result, post)
+// close closes database connections
+func (db *db) close() error {
+	return db.Close()
+}
+
+// checkSchema returns error unless all migrations known to this build are applied
+func (db *db) checkSchema(ctx context.Context) (err error) {
+	ctx, done := db.start(ctx, "checkSchema")
+	defer done(&err)
+
+	var version int
+	if err := queryRowContext(ctx, db, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version); err != nil {
+		return err
 	}
 
-	if err = rows.Err(); err != nil {
+	if version < latestVersion() {
+		return fmt.Errorf("schema version is %d, want %d", version, latestVersion())
+	}
+
+	return nil
+}
+
+const (
+	postColumns = postMetaColumns + ", body"
+	// postColumnsNoBody selects empty body in place of actual one
+	postColumnsNoBody = postMetaColumns + ", ''"
+	postMetaColumns   = "uid, user_uid, category_uid, title, url, created_at, modified_at, upvotes, downvotes, hot_rank, controversy, kind, deleted_at, revision, version"
+)
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPost reads post selected with postColumns followed by extra columns
+func scanPost(row scanner, extra ...interface{}) (*Post, error) {
+	post := new(Post)
+	var uid, userUID, categoryUID string
+	var deletedAt pq.NullTime
+	dest := []interface{}{&uid, &userUID, &categoryUID, &post.Title, &post.URL, &post.CreatedAt, &post.ModifiedAt, &post.Upvotes, &post.Downvotes, &post.HotRank, &post.Controversy, &post.Kind, &deletedAt, &post.Revision, &post.Version, &post.Body}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
 		return nil, err
 	}
 
-	return result, nil
+	if deletedAt.Valid {
+		post.DeletedAt = deletedAt.Time
+	}
+
+	post.UID, err = uuid.Parse(uid)
+	if err != nil {
+		return nil, err
+	}
+
+	post.UserUID, err = uuid.Parse(userUID)
+	if err != nil {
+		return nil, err
+	}
+
+	post.CategoryUID, err = uuid.Parse(categoryUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return post, nil
 }
 
-func (db *db) getAllPostsByCategory(categoryUID uuid.UUID, pageSize, pageNumber int32) ([]*Post, error) {
-	query := "SELECT uid, user_uid, title, url, created_at, modified_at FROM posts WHERE category_uid=$1 ORDER BY created_at DESC LIMIT $2 OFFSET $3"
-	lastRecord := pageNumber * pageSize
-	rows, err := db.Query(query, categoryUID.String(), pageSize, lastRecord)
+func (db *db) getAllPosts(ctx context.Context, q listQuery) ([]*Post, error) {
+	return db.listPosts(ctx, []string{"deleted_at IS NULL"}, nil, q)
+}
+
+func (db *db) getAllPostsByCategory(ctx context.Context, categoryUID uuid.UUID, q listQuery) ([]*Post, error) {
+	return db.listPosts(ctx, []string{"category_uid=$1", "deleted_at IS NULL"}, []interface{}{categoryUID.String()}, q)
+}
+
+// listPosts returns a page of posts matching conditions in sort order
+func (db *db) listPosts(ctx context.Context, conditions []string, args []interface{}, q listQuery) (_ []*Post, err error) {
+	ctx, done := db.start(ctx, "listPosts")
+	defer done(&err)
+
+	if !q.since.IsZero() {
+		args = append(args, q.since)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+
+	orderBy := "created_at DESC, uid DESC"
+	rank := q.sort.rankColumn()
+	if rank != "" {
+		orderBy = rank + " DESC, " + orderBy
+	}
+
+	if q.cursor != nil {
+		args = append(args, q.cursor.CreatedAt, q.cursor.UID.String())
+		n := len(args)
+		if rank == "" {
+			conditions = append(conditions, fmt.Sprintf("(created_at, uid) < ($%d, $%d)", n-1, n))
+		} else {
+			args = append(args, q.cursor.Rank)
+			conditions = append(conditions, fmt.Sprintf("(%s, created_at, uid) < ($%d, $%d, $%d)", rank, n+1, n-1, n))
+		}
+	}
+
+	columns := postColumns
+	if q.omitBody {
+		columns = postColumnsNoBody
+	}
+
+	query := "SELECT " + columns + " FROM posts"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, q.pageSize)
+	query += fmt.Sprintf(" ORDER BY %s LIMIT $%d", orderBy, len(args))
+	if q.cursor == nil {
+		args = append(args, q.pageNumber*q.pageSize)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	rows, err := queryContext(ctx, db, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -99,25 +296,11 @@ func (db *db) getAllPostsByCategory(categoryUID uuid.UUID, pageSize, pageNumber
 	defer rows.Close()
 	result := make([]*Post, 0)
 	for rows.Next() {
-		post := new(Post)
-		var uid, userUID string
-		err := rows.Scan(&uid, &userUID, &post.Title, &post.URL, &post.CreatedAt, &post.ModifiedAt)
-		if err != nil {
-			return nil, err
-		}
-
-		post.UID, err = uuid.Parse(uid)
-		if err != nil {
-			return nil, err
-		}
-
-		post.UserUID, err = uuid.Parse(userUID)
+		post, err := scanPost(rows)
 		if err != nil {
 			return nil, err
 		}
 
-		post.CategoryUID = categoryUID
-
 		result = append(result, post)
 	}
 
@@ -125,31 +308,25 @@ func (db *db) getAllPostsByCategory(categoryUID uuid.UUID, pageSize, pageNumber
 		return nil, err
 	}
 
+	traceRows(ctx, int64(len(result)))
 	return result, nil
 }
 
-func (db *db) getOnePost(uid uuid.UUID) (*Post, error) {
-	query := "SELECT user_uid, category_uid, title, url, created_at, modified_at FROM posts WHERE uid=$1"
-	row := db.QueryRow(query, uid.String())
-	result := new(Post)
-	var stringUserUID, stringCategoryUUID string
-	switch err := row.Scan(&stringUserUID, &stringCategoryUUID, &result.Title, &result.URL, &result.CreatedAt, &result.ModifiedAt); err {
-	case nil:
-		result.UID = uid
-		userUID, err := uuid.Parse(stringUserUID)
-		if err != nil {
-			return nil, err
-		}
-
-		result.UserUID = userUID
+// getOnePost returns post by UID, deleted post is found only if includeDeleted is set
+func (db *db) getOnePost(ctx context.Context, uid uuid.UUID, includeDeleted bool) (_ *Post, err error) {
+	ctx, done := db.start(ctx, "getOnePost")
+	defer done(&err)
 
-		categoryUID, err := uuid.Parse(stringCategoryUUID)
-		if err != nil {
-			return nil, err
-		}
+	query := "SELECT " + postColumns + " FROM posts WHERE uid=$1"
+	if !includeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
 
-		result.CategoryUID = categoryUID
-		return result, nil
+	row := queryRowContext(ctx, db, query, uid.String())
+	switch post, err := scanPost(row); err {
+	case nil:
+		traceRows(ctx, 1)
+		return post, nil
 	case sql.ErrNoRows:
 		return nil, errNotFound
 	default:
@@ -157,42 +334,238 @@ func (db *db) getOnePost(uid uuid.UUID) (*Post, error) {
 	}
 }
 
-func (db *db) createPost(title, url string, userUID, categoryUID uuid.UUID) (*Post, error) {
+// saveRevision copies current contents of post to its revisions
+func saveRevision(ctx context.Context, tx *sql.Tx, uid uuid.UUID) error {
+	query := "INSERT INTO post_revisions (post_uid, revision, title, url, body, created_at) SELECT uid, revision, title, url, body, modified_at FROM posts WHERE uid=$1"
+	_, err := execContext(ctx, tx, query, uid.String())
+	return err
+}
+
+// createPost saves new post with contents of p. If idempotency key is set and user created a post
+// with the same key less than keyTTL ago, that post is returned instead and created is false
+func (db *db) createPost(ctx context.Context, p *Post, idempotencyKey string, keyTTL time.Duration) (_ *Post, created bool, err error) {
+	ctx, done := db.start(ctx, "createPost")
+	defer done(&err)
+
 	post := new(Post)
 
-	query := "INSERT INTO posts (uid, user_uid, category_uid, title, url, created_at, modified_at) VALUES ($1, $2, $3, $4, $5, $6, $7)"
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	defer tx.Rollback()
+
+	if idempotencyKey != "" {
+		// expired key can be reused
+		query := "DELETE FROM idempotency_keys WHERE user_uid=$1 AND key=$2 AND created_at < $3"
+		_, err := execContext(ctx, tx, query, p.UserUID.String(), idempotencyKey, time.Now().Add(-keyTTL))
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	query := "INSERT INTO posts (uid, user_uid, category_uid, title, url, body, kind, created_at, modified_at, hot_rank) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)"
 	uid := uuid.New()
 
 	now := time.Now()
 
 	post.UID = uid
-	post.UserUID = userUID
-	post.CategoryUID = categoryUID
-	post.Title = title
-	post.URL = url
+	post.UserUID = p.UserUID
+	post.CategoryUID = p.CategoryUID
+	post.Title = p.Title
+	post.URL = p.URL
+	post.Body = p.Body
+	post.Kind = p.Kind
 	post.CreatedAt = now
 	post.ModifiedAt = now
+	post.HotRank = hotRank(0, now)
+	post.Revision = 1
+	post.Version = 1
 
-	result, err := db.Exec(query, post.UID.String(), userUID.String(), categoryUID.String(), post.Title, post.URL, post.CreatedAt, post.ModifiedAt)
+	result, err := execContext(ctx, tx, query, post.UID.String(), post.UserUID.String(), post.CategoryUID.String(), post.Title, post.URL, post.Body, post.Kind, post.CreatedAt, post.ModifiedAt, post.HotRank)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	nRows, err := result.RowsAffected()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if nRows == 0 {
+		return nil, false, errPostNotCreated
+	}
+
+	if err := saveRevision(ctx, tx, post.UID); err != nil {
+		return nil, false, err
+	}
+
+	if idempotencyKey != "" {
+		// concurrent request with the same key waits here until the first one finishes
+		query = "INSERT INTO idempotency_keys (user_uid, key, post_uid, created_at) VALUES ($1, $2, $3, $4) ON CONFLICT (user_uid, key) DO NOTHING"
+		result, err := execContext(ctx, tx, query, post.UserUID.String(), idempotencyKey, post.UID.String(), now)
+		if err != nil {
+			return nil, false, err
+		}
+
+		nRows, err := result.RowsAffected()
+		if err != nil {
+			return nil, false, err
+		}
+
+		if nRows == 0 {
+			tx.Rollback()
+			post, err := db.getIdempotentPost(ctx, post.UserUID, idempotencyKey)
+			return post, false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+
+	return post, true, nil
+}
+
+// getIdempotentPost returns post created by user with idempotency key
+func (db *db) getIdempotentPost(ctx context.Context, userUID uuid.UUID, idempotencyKey string) (*Post, error) {
+	query := "SELECT post_uid FROM idempotency_keys WHERE user_uid=$1 AND key=$2"
+	var postUID string
+	if err := queryRowContext(ctx, db, query, userUID.String(), idempotencyKey).Scan(&postUID); err != nil {
+		return nil, err
+	}
+
+	uid, err := uuid.Parse(postUID)
 	if err != nil {
 		return nil, err
 	}
 
+	return db.getOnePost(ctx, uid, true)
+}
+
+// purgeIdempotencyKeys removes idempotency keys created before t and returns their number
+func (db *db) purgeIdempotencyKeys(ctx context.Context, t time.Time) (_ int64, err error) {
+	ctx, done := db.start(ctx, "purgeIdempotencyKeys")
+	defer done(&err)
+
+	query := "DELETE FROM idempotency_keys WHERE created_at < $1"
+	result, err := execContext(ctx, db, query, t)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// conditionFailed tells why conditional write of not deleted post changed nothing.
+// Write made in transaction must be checked through it, pool may have no other connection to give
+func conditionFailed(ctx context.Context, q queryer, uid uuid.UUID) error {
+	query := "SELECT EXISTS(SELECT 1 FROM posts WHERE uid=$1 AND deleted_at IS NULL)"
+	var exists bool
+	if err := queryRowContext(ctx, q, query, uid.String()).Scan(&exists); err != nil {
+		return err
+	}
+
+	if exists {
+		return errVersionMismatch
+	}
+
+	return errNotFound
+}
+
+// updatePost changes post contents, saves them as new revision and returns new post version.
+// Zero expectedVersion updates post regardless of its version. Empty update writes nothing and returns current version
+func (db *db) updatePost(ctx context.Context, uid uuid.UUID, u postUpdate, expectedVersion int64) (_ int64, err error) {
+	ctx, done := db.start(ctx, "updatePost")
+	defer done(&err)
+
+	if u.empty() {
+		query := "SELECT version FROM posts WHERE uid=$1 AND deleted_at IS NULL AND ($2::BIGINT=0 OR version=$2)"
+		var version int64
+		switch err := queryRowContext(ctx, db, query, uid.String(), expectedVersion).Scan(&version); err {
+		case nil:
+			return version, nil
+		case sql.ErrNoRows:
+			return 0, conditionFailed(ctx, db, uid)
+		default:
+			return 0, err
+		}
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback()
+
+	args := []interface{}{time.Now()}
+	set := []string{"modified_at=$1", "revision=revision+1", "version=version+1"}
+	fields := []struct {
+		column string
+		value  *string
+	}{{"title", u.title}, {"url", u.url}, {"body", u.body}}
+	for _, f := range fields {
+		if f.value != nil {
+			args = append(args, *f.value)
+			set = append(set, fmt.Sprintf("%s=$%d", f.column, len(args)))
+		}
+	}
+
+	args = append(args, uid.String(), expectedVersion)
+	query := fmt.Sprintf("UPDATE posts SET %s WHERE uid=$%d AND deleted_at IS NULL AND ($%d::BIGINT=0 OR version=$%d) RETURNING version", strings.Join(set, ", "), len(args)-1, len(args), len(args))
+	var version int64
+	switch err := queryRowContext(ctx, tx, query, args...).Scan(&version); err {
+	case nil:
+	case sql.ErrNoRows:
+		return 0, conditionFailed(ctx, tx, uid)
+	default:
+		return 0, err
+	}
+
+	if err := saveRevision(ctx, tx, uid); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// deletePost marks post as deleted, it's kept until purged.
+// Zero expectedVersion deletes post regardless of its version
+func (db *db) deletePost(ctx context.Context, uid uuid.UUID, expectedVersion int64) (err error) {
+	ctx, done := db.start(ctx, "deletePost")
+	defer done(&err)
+
+	query := "UPDATE posts SET deleted_at=$1, version=version+1 WHERE uid=$2 AND deleted_at IS NULL AND ($3::BIGINT=0 OR version=$3)"
+	result, err := execContext(ctx, db, query, time.Now(), uid.String(), expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	nRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
 	if nRows == 0 {
-		return nil, errPostNotCreated
+		return conditionFailed(ctx, db, uid)
 	}
 
-	return post, nil
+	return nil
 }
 
-func (db *db) updatePost(uid uuid.UUID, title, url string) error {
-	query := "UPDATE posts SET title=COALESCE(NULLIF($1,''), title), url=COALESCE(NULLIF($2,''), url), modified_at=$3 WHERE uid=$4"
-	result, err := db.Exec(query, title, url, time.Now(), uid.String())
+// restorePost undoes deletion of post
+func (db *db) restorePost(ctx context.Context, uid uuid.UUID) (err error) {
+	ctx, done := db.start(ctx, "restorePost")
+	defer done(&err)
+
+	query := "UPDATE posts SET deleted_at=NULL, version=version+1 WHERE uid=$1 AND deleted_at IS NOT NULL"
+	result, err := execContext(ctx, db, query, uid.String())
 	if err != nil {
 		return err
 	}
@@ -209,9 +582,13 @@ func (db *db) updatePost(uid uuid.UUID, title, url string) error {
 	return nil
 }
 
-func (db *db) deletePost(uid uuid.UUID) error {
+// purgePost removes post permanently, deleted or not
+func (db *db) purgePost(ctx context.Context, uid uuid.UUID) (err error) {
+	ctx, done := db.start(ctx, "purgePost")
+	defer done(&err)
+
 	query := "DELETE FROM posts WHERE uid=$1"
-	result, err := db.Exec(query, uid.String())
+	result, err := execContext(ctx, db, query, uid.String())
 	if err != nil {
 		return err
 	}
@@ -228,9 +605,26 @@ func (db *db) deletePost(uid uuid.UUID) error {
 	return nil
 }
 
-func (db *db) checkPostExists(uid uuid.UUID) (bool, error) {
-	query := "SELECT EXISTS(SELECT 1 FROM posts WHERE uid=$1)"
-	row := db.QueryRow(query, uid.String())
+// purgeDeleted removes posts deleted before t and returns their number
+func (db *db) purgeDeleted(ctx context.Context, t time.Time) (_ int64, err error) {
+	ctx, done := db.start(ctx, "purgeDeleted")
+	defer done(&err)
+
+	query := "DELETE FROM posts WHERE deleted_at < $1"
+	result, err := execContext(ctx, db, query, t)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (db *db) checkPostExists(ctx context.Context, uid uuid.UUID) (_ bool, err error) {
+	ctx, done := db.start(ctx, "checkPostExists")
+	defer done(&err)
+
+	query := "SELECT EXISTS(SELECT 1 FROM posts WHERE uid=$1 AND deleted_at IS NULL)"
+	row := queryRowContext(ctx, db, query, uid.String())
 	var result bool
 	switch err := row.Scan(&result); err {
 	case nil:
@@ -242,12 +636,16 @@ func (db *db) checkPostExists(uid uuid.UUID) (bool, error) {
 	}
 }
 
-func (db *db) getPostOwner(uid uuid.UUID) (string, error) {
+func (db *db) getPostOwner(ctx context.Context, uid uuid.UUID) (_ string, err error) {
+	ctx, done := db.start(ctx, "getPostOwner")
+	defer done(&err)
+
 	query := "SELECT user_uid FROM posts WHERE uid=$1"
-	row := db.QueryRow(query, uid.String())
+	row := queryRowContext(ctx, db, query, uid.String())
 	var result string
 	switch err := row.Scan(&result); err {
 	case nil:
+		traceRows(ctx, 1)
 		return result, nil
 	case sql.ErrNoRows:
 		return "", errNotFound
@@ -255,3 +653,192 @@ func (db *db) getPostOwner(uid uuid.UUID) (string, error) {
 		return "", err
 	}
 }
+
+func (db *db) vote(ctx context.Context, postUID, userUID uuid.UUID, value int) (int32, int32, error) {
+	return db.setVote(ctx, postUID, userUID, value)
+}
+
+func (db *db) removeVote(ctx context.Context, postUID, userUID uuid.UUID) (int32, int32, error) {
+	return db.setVote(ctx, postUID, userUID, 0)
+}
+
+// setVote stores user's vote (0 removes it) and recounts post votes
+func (db *db) setVote(ctx context.Context, postUID, userUID uuid.UUID, value int) (_, _ int32, err error) {
+	ctx, done := db.start(ctx, "setVote")
+	defer done(&err)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	defer tx.Rollback()
+
+	// post row lock makes concurrent votes for the same post recount one by one
+	query := "SELECT created_at FROM posts WHERE uid=$1 AND deleted_at IS NULL FOR UPDATE"
+	var createdAt time.Time
+	switch err := queryRowContext(ctx, tx, query, postUID.String()).Scan(&createdAt); err {
+	case nil:
+	case sql.ErrNoRows:
+		return 0, 0, errNotFound
+	default:
+		return 0, 0, err
+	}
+
+	if value == 0 {
+		query = "DELETE FROM votes WHERE post_uid=$1 AND user_uid=$2"
+		_, err = execContext(ctx, tx, query, postUID.String(), userUID.String())
+	} else {
+		query = "INSERT INTO votes (post_uid, user_uid, value, created_at) VALUES ($1, $2, $3, $4) ON CONFLICT (post_uid, user_uid) DO UPDATE SET value=EXCLUDED.value"
+		_, err = execContext(ctx, tx, query, postUID.String(), userUID.String(), value, time.Now())
+	}
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	query = "SELECT COUNT(*) FILTER (WHERE value=1), COUNT(*) FILTER (WHERE value=-1) FROM votes WHERE post_uid=$1"
+	var upvotes, downvotes int32
+	if err := queryRowContext(ctx, tx, query, postUID.String()).Scan(&upvotes, &downvotes); err != nil {
+		return 0, 0, err
+	}
+
+	query = "UPDATE posts SET upvotes=$1, downvotes=$2, score=$3, hot_rank=$4, controversy=$5 WHERE uid=$6"
+	score := upvotes - downvotes
+	_, err = execContext(ctx, tx, query, upvotes, downvotes, score, hotRank(score, createdAt), controversy(upvotes, downvotes), postUID.String())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+
+	return upvotes, downvotes, nil
+}
+
+// escapedTitle is post title escaped like html.EscapeString does, so its highlight is safe to render as HTML
+const escapedTitle = `replace(replace(replace(replace(replace(title, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;'), '''', '&#39;')`
+
+// searchPosts returns posts matching full-text query, most relevant first. Nil category or user UID matches any
+func (db *db) searchPosts(ctx context.Context, text string, categoryUID, userUID uuid.UUID, pageSize, pageNumber int32) (_ []*SearchResult, err error) {
+	ctx, done := db.start(ctx, "searchPosts")
+	defer done(&err)
+
+	conditions := []string{"search_vector @@ query", "deleted_at IS NULL"}
+	args := []interface{}{text}
+	if categoryUID != uuid.Nil {
+		args = append(args, categoryUID.String())
+		conditions = append(conditions, fmt.Sprintf("category_uid=$%d", len(args)))
+	}
+
+	if userUID != uuid.Nil {
+		args = append(args, userUID.String())
+		conditions = append(conditions, fmt.Sprintf("user_uid=$%d", len(args)))
+	}
+
+	args = append(args, pageSize, pageNumber*pageSize)
+	query := "SELECT " + postColumns + ", ts_rank(search_vector, query) AS relevance, ts_headline('english', " + escapedTitle + ", query, 'StartSel=<b>, StopSel=</b>, HighlightAll=true') " +
+		"FROM posts, plainto_tsquery('english', $1) query WHERE " + strings.Join(conditions, " AND ") +
+		fmt.Sprintf(" ORDER BY relevance DESC, created_at DESC, uid DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := queryContext(ctx, db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	result := make([]*SearchResult, 0)
+	for rows.Next() {
+		r := new(SearchResult)
+		r.Post, err = scanPost(rows, &r.Relevance, &r.TitleHighlight)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	traceRows(ctx, int64(len(result)))
+	return result, nil
+}
+
+const revisionColumns = "post_uid, revision, title, url, body, created_at"
+
+// scanRevision reads revision selected with revisionColumns
+func scanRevision(row scanner) (*Revision, error) {
+	revision := new(Revision)
+	var postUID string
+	err := row.Scan(&postUID, &revision.Number, &revision.Title, &revision.URL, &revision.Body, &revision.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	revision.PostUID, err = uuid.Parse(postUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+// listRevisions returns revisions of not deleted post, latest first
+func (db *db) listRevisions(ctx context.Context, uid uuid.UUID, pageSize, pageNumber int32) (_ []*Revision, err error) {
+	ctx, done := db.start(ctx, "listRevisions")
+	defer done(&err)
+
+	exists, err := db.checkPostExists(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, errNotFound
+	}
+
+	query := "SELECT " + revisionColumns + " FROM post_revisions WHERE post_uid=$1 ORDER BY revision DESC LIMIT $2 OFFSET $3"
+	rows, err := queryContext(ctx, db, query, uid.String(), pageSize, pageNumber*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	result := make([]*Revision, 0)
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, revision)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	traceRows(ctx, int64(len(result)))
+	return result, nil
+}
+
+// getRevision returns revision of not deleted post by its number
+func (db *db) getRevision(ctx context.Context, uid uuid.UUID, number int32) (_ *Revision, err error) {
+	ctx, done := db.start(ctx, "getRevision")
+	defer done(&err)
+
+	query := "SELECT " + revisionColumns + " FROM post_revisions WHERE post_uid=$1 AND revision=$2 AND EXISTS(SELECT 1 FROM posts WHERE uid=$1 AND deleted_at IS NULL)"
+	row := queryRowContext(ctx, db, query, uid.String(), number)
+	switch revision, err := scanRevision(row); err {
+	case nil:
+		traceRows(ctx, 1)
+		return revision, nil
+	case sql.ErrNoRows:
+		return nil, errNotFound
+	default:
+		return nil, err
+	}
+}
@@ -1,34 +1,35 @@
 package post
 
 import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
 	pb "github.com/andreymgn/RSOI-post/pkg/post/proto"
 	"github.com/golang/protobuf/ptypes"
-	"github.com/google/uuid"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 var (
-	statusNoPostTitle = status.Error(codes.InvalidArgument, "post title is required")
-	statusNotFound    = status.Error(codes.NotFound, "post not found")
-	statusInvalidUUID = status.Error(codes.InvalidArgument, "invalid UUID")
+	statusNotFound         = status.Error(codes.NotFound, "post not found")
+	statusInvalidPageToken = status.Error(codes.InvalidArgument, "invalid page token")
+	statusRevisionNotFound = status.Error(codes.NotFound, "post revision not found")
+	statusVersionMismatch  = status.Error(codes.Aborted, "post was modified, expected version is stale")
 )
 
-func internalError(err error) error {
-	return status.Error(codes.Internal, err.Error())
-}
-
 // SinglePost converts Post to SinglePost
 func (p *Post) SinglePost() (*pb.SinglePost, error) {
 	createdAtProto, err := ptypes.TimestampProto(p.CreatedAt)
 	if err != nil {
-		return nil, internalError(err)
+		return nil, errorStatus(err)
 	}
 
 	modifiedAtProto, err := ptypes.TimestampProto(p.ModifiedAt)
 	if err != nil {
-		return nil, internalError(err)
+		return nil, errorStatus(err)
 	}
 
 	res := new(pb.SinglePost)
@@ -39,23 +40,97 @@ func (p *Post) SinglePost() (*pb.SinglePost, error) {
 	res.Url = p.URL
 	res.CreatedAt = createdAtProto
 	res.ModifiedAt = modifiedAtProto
+	res.Upvotes = p.Upvotes
+	res.Downvotes = p.Downvotes
+	res.Score = p.Score()
+	res.Kind = pb.PostKind(p.Kind)
+	res.Body = p.Body
+	res.Revision = p.Revision
+	res.Edited = p.Revision > 1
+	res.Version = p.Version
+	if !p.DeletedAt.IsZero() {
+		res.DeletedAt, err = ptypes.TimestampProto(p.DeletedAt)
+		if err != nil {
+			return nil, errorStatus(err)
+		}
+	}
 
 	return res, nil
 }
 
-// ListPostsByCategory returns newest posts in category
-func (s *Server) ListPosts(ctx context.Context, req *pb.ListPostsRequest) (*pb.ListPostsResponse, error) {
-	var pageSize int32
-	if req.PageSize == 0 {
-		pageSize = 10
-	} else {
-		pageSize = req.PageSize
+var sortOrders = map[pb.SortOrder]sortOrder{
+	pb.SortOrder_NEW:           sortNew,
+	pb.SortOrder_HOT:           sortHot,
+	pb.SortOrder_TOP:           sortTop,
+	pb.SortOrder_CONTROVERSIAL: sortControversial,
+}
+
+var timeWindows = map[pb.TimeWindow]time.Duration{
+	pb.TimeWindow_ALL:   0,
+	pb.TimeWindow_DAY:   24 * time.Hour,
+	pb.TimeWindow_WEEK:  7 * 24 * time.Hour,
+	pb.TimeWindow_MONTH: 30 * 24 * time.Hour,
+}
+
+// listRequest is implemented by requests for a page of posts
+type listRequest interface {
+	GetPageSize() int32
+	GetPageNumber() int32
+	GetPageToken() string
+	GetSort() pb.SortOrder
+	GetWindow() pb.TimeWindow
+	GetOmitBody() bool
+}
+
+// pageSize returns requested page size, or default one if it's not requested
+func (s *Server) pageSize(requested int32) int32 {
+	if requested == 0 {
+		return s.conf.DefaultPageSize
 	}
 
-	posts, err := s.db.getAllPosts(pageSize, req.PageNumber)
-	if err != nil {
-		return nil, internalError(err)
+	return requested
+}
+
+// newListQuery makes query for page of posts, page token takes precedence over page number.
+// Problems with request are added to v
+func (s *Server) newListQuery(v *fieldViolations, req listRequest) (listQuery, error) {
+	q := listQuery{pageNumber: req.GetPageNumber(), omitBody: req.GetOmitBody()}
+	q.pageSize = s.checkPage(v, req.GetPageSize(), req.GetPageNumber())
+
+	sort, ok := sortOrders[req.GetSort()]
+	if !ok {
+		v.add("sort", "is unknown sort order")
+	}
+
+	q.sort = sort
+
+	window, ok := timeWindows[req.GetWindow()]
+	if !ok {
+		v.add("window", "is unknown time window")
+	}
+
+	if window != 0 {
+		q.since = time.Now().Add(-window)
+	}
+
+	if err := v.err(); err != nil {
+		return q, err
+	}
+
+	if req.GetPageToken() != "" {
+		cursor, err := decodePageToken(req.GetPageToken())
+		if err != nil || cursor.Sort != q.sort {
+			return q, statusInvalidPageToken
+		}
+
+		q.cursor = cursor
 	}
+
+	return q, nil
+}
+
+// listResponse converts page of posts to ListPostsResponse
+func listResponse(posts []*Post, q listQuery) (*pb.ListPostsResponse, error) {
 	res := new(pb.ListPostsResponse)
 	for _, post := range posts {
 		postResponse, err := post.SinglePost()
@@ -66,132 +141,369 @@ func (s *Server) ListPosts(ctx context.Context, req *pb.ListPostsRequest) (*pb.L
 		res.Posts = append(res.Posts, postResponse)
 	}
 
-	res.PageSize = pageSize
-	res.PageNumber = req.PageNumber
+	// full page means there may be more posts
+	if len(posts) > 0 && len(posts) == int(q.pageSize) {
+		token, err := cursorAfter(posts[len(posts)-1], q.sort).encode()
+		if err != nil {
+			return nil, errorStatus(err)
+		}
+
+		res.NextPageToken = token
+	}
+
+	res.PageSize = q.pageSize
+	res.PageNumber = q.pageNumber
 
 	return res, nil
 }
 
-// ListPostsByCategory returns newest posts in category
-func (s *Server) ListPostsByCategory(ctx context.Context, req *pb.ListPostsByCategoryRequest) (*pb.ListPostsResponse, error) {
-	var pageSize int32
-	if req.PageSize == 0 {
-		pageSize = 10
-	} else {
-		pageSize = req.PageSize
+// bodyTooLong checks if post body exceeds configured limit
+func (s *Server) bodyTooLong(body string) bool {
+	return s.conf.MaxBodyLength > 0 && utf8.RuneCountInString(body) > s.conf.MaxBodyLength
+}
+
+// PostRevision converts Revision to PostRevision
+func (r *Revision) PostRevision() (*pb.PostRevision, error) {
+	createdAtProto, err := ptypes.TimestampProto(r.CreatedAt)
+	if err != nil {
+		return nil, errorStatus(err)
 	}
 
-	uid, err := uuid.Parse(req.CategoryUid)
+	res := new(pb.PostRevision)
+	res.PostUid = r.PostUID.String()
+	res.Revision = r.Number
+	res.Title = r.Title
+	res.Url = r.URL
+	res.Body = r.Body
+	res.CreatedAt = createdAtProto
+
+	return res, nil
+}
+
+// ListPosts returns posts in requested order, newest first by default
+func (s *Server) ListPosts(ctx context.Context, req *pb.ListPostsRequest) (*pb.ListPostsResponse, error) {
+	var v fieldViolations
+	q, err := s.newListQuery(&v, req)
 	if err != nil {
-		return nil, statusInvalidUUID
+		return nil, err
 	}
 
-	posts, err := s.db.getAllPostsByCategory(uid, pageSize, req.PageNumber)
+	posts, err := s.db.getAllPosts(ctx, q)
 	if err != nil {
-		return nil, internalError(err)
+		return nil, errorStatus(err)
 	}
-	res := new(pb.ListPostsResponse)
-	for _, post := range posts {
-		postResponse, err := post.SinglePost()
-		if err != nil {
-			return nil, err
-		}
 
-		res.Posts = append(res.Posts, postResponse)
+	return listResponse(posts, q)
+}
+
+// ListPostsByCategory returns posts in category in requested order, newest first by default
+func (s *Server) ListPostsByCategory(ctx context.Context, req *pb.ListPostsByCategoryRequest) (*pb.ListPostsResponse, error) {
+	var v fieldViolations
+	uid := v.uid("categoryUid", req.CategoryUid)
+	q, err := s.newListQuery(&v, req)
+	if err != nil {
+		return nil, err
 	}
 
-	res.PageSize = pageSize
-	res.PageNumber = req.PageNumber
+	posts, err := s.db.getAllPostsByCategory(ctx, uid, q)
+	if err != nil {
+		return nil, errorStatus(err)
+	}
 
-	return res, nil
+	return listResponse(posts, q)
 }
 
-// GetPost returns single post by ID
+// GetPost returns single post by ID, deleted posts are returned only if requested
 func (s *Server) GetPost(ctx context.Context, req *pb.GetPostRequest) (*pb.SinglePost, error) {
-	uid, err := uuid.Parse(req.Uid)
-	if err != nil {
-		return nil, statusInvalidUUID
+	var v fieldViolations
+	uid := v.uid("uid", req.Uid)
+	if err := v.err(); err != nil {
+		return nil, err
 	}
 
-	post, err := s.db.getOnePost(uid)
+	post, err := s.db.getOnePost(ctx, uid, req.IncludeDeleted)
 	switch err {
 	case nil:
 		return post.SinglePost()
 	case errNotFound:
 		return nil, statusNotFound
 	default:
-		return nil, internalError(err)
+		return nil, errorStatus(err)
 	}
 }
 
-// CreatePost creates a new post
+// CreatePost creates a new post, retry with the same idempotency key returns post created by first request
 func (s *Server) CreatePost(ctx context.Context, req *pb.CreatePostRequest) (*pb.SinglePost, error) {
-	if req.Title == "" {
-		return nil, statusNoPostTitle
+	var v fieldViolations
+	v.title(req.Title)
+	v.url(req.Url)
+	s.checkBody(&v, req.Body)
+	userUID := v.uid("userUid", req.UserUid)
+	categoryUID := v.uid("categoryUid", req.CategoryUid)
+
+	post := &Post{UserUID: userUID, CategoryUID: categoryUID, Title: req.Title, URL: req.Url, Body: req.Body}
+	switch req.Kind {
+	case pb.PostKind_LINK:
+		post.Kind = LinkPost
+		if req.Body != "" {
+			v.add("body", "must be empty in link post")
+		}
+	case pb.PostKind_TEXT:
+		post.Kind = TextPost
+		if req.Url != "" {
+			v.add("url", "must be empty in text post")
+		}
+	default:
+		v.add("kind", "must be LINK or TEXT")
 	}
 
-	userUID, err := uuid.Parse(req.UserUid)
-	if err != nil {
-		return nil, statusInvalidUUID
+	if len(req.IdempotencyKey) > maxIdempotencyKeyLength {
+		v.add("idempotencyKey", fmt.Sprintf("must be at most %d bytes long", maxIdempotencyKeyLength))
 	}
 
-	categoryUID, err := uuid.Parse(req.CategoryUid)
-	if err != nil {
-		return nil, statusInvalidUUID
+	if err := v.err(); err != nil {
+		return nil, err
 	}
 
-	post, err := s.db.createPost(req.Title, req.Url, userUID, categoryUID)
+	post, created, err := s.db.createPost(ctx, post, req.IdempotencyKey, s.conf.IdempotencyKeyTTL)
 	if err != nil {
-		return nil, internalError(err)
+		return nil, errorStatus(err)
+	}
+
+	// retry with idempotency key returns post created earlier
+	if created {
+		postsCreated.WithLabelValues(post.CategoryUID.String()).Inc()
 	}
 
 	return post.SinglePost()
 }
 
-// UpdatePost updates post by ID
+// newPostUpdate returns fields listed in update mask, without mask non-empty fields are updated.
+// Unknown mask paths are added to v
+func newPostUpdate(v *fieldViolations, req *pb.UpdatePostRequest) postUpdate {
+	var u postUpdate
+	if req.UpdateMask == nil {
+		if req.Title != "" {
+			u.title = &req.Title
+		}
+
+		if req.Url != "" {
+			u.url = &req.Url
+		}
+
+		if req.Body != "" {
+			u.body = &req.Body
+		}
+
+		return u
+	}
+
+	for _, path := range req.UpdateMask.Paths {
+		switch path {
+		case "title":
+			u.title = &req.Title
+		case "url":
+			u.url = &req.Url
+		case "body":
+			u.body = &req.Body
+		default:
+			v.add("updateMask", fmt.Sprintf("has unknown field %q", path))
+		}
+	}
+
+	return u
+}
+
+// UpdatePost updates post by ID, if expected version is set post is updated only if it has that version.
+// Update mask lists fields to write, including empty ones, without mask only non-empty fields are written
 func (s *Server) UpdatePost(ctx context.Context, req *pb.UpdatePostRequest) (*pb.UpdatePostResponse, error) {
-	uid, err := uuid.Parse(req.Uid)
-	if err != nil {
-		return nil, statusInvalidUUID
+	var v fieldViolations
+	uid := v.uid("uid", req.Uid)
+	u := newPostUpdate(&v, req)
+	if u.title != nil {
+		v.title(*u.title)
 	}
 
-	err = s.db.updatePost(uid, req.Title, req.Url)
+	if u.url != nil {
+		v.url(*u.url)
+	}
+
+	if u.body != nil {
+		s.checkBody(&v, *u.body)
+	}
+
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	setsBody := u.body != nil && *u.body != ""
+	setsURL := u.url != nil && *u.url != ""
+
+	// only text posts have body and only link posts have URL
+	if setsBody || setsURL {
+		post, err := s.db.getOnePost(ctx, uid, false)
+		switch err {
+		case nil:
+		case errNotFound:
+			return nil, statusNotFound
+		default:
+			return nil, errorStatus(err)
+		}
+
+		if post.Kind == LinkPost && setsBody {
+			v.add("body", "must be empty in link post")
+		}
+
+		if post.Kind == TextPost && setsURL {
+			v.add("url", "must be empty in text post")
+		}
+
+		if err := v.err(); err != nil {
+			return nil, err
+		}
+	}
+
+	version, err := s.db.updatePost(ctx, uid, u, req.ExpectedVersion)
 	switch err {
 	case nil:
-		return new(pb.UpdatePostResponse), nil
+		res := new(pb.UpdatePostResponse)
+		res.Version = version
+		return res, nil
 	case errNotFound:
 		return nil, statusNotFound
+	case errVersionMismatch:
+		return nil, statusVersionMismatch
 	default:
-		return nil, internalError(err)
+		return nil, errorStatus(err)
 	}
 }
 
-// DeletePost deletes post by ID
+// DeletePost deletes post by ID, it can be restored until purged.
+// If expected version is set post is deleted only if it has that version
 func (s *Server) DeletePost(ctx context.Context, req *pb.DeletePostRequest) (*pb.DeletePostResponse, error) {
-	uid, err := uuid.Parse(req.Uid)
-	if err != nil {
-		return nil, statusInvalidUUID
+	var v fieldViolations
+	uid := v.uid("uid", req.Uid)
+	if err := v.err(); err != nil {
+		return nil, err
 	}
 
-	err = s.db.deletePost(uid)
+	err := s.db.deletePost(ctx, uid, req.ExpectedVersion)
 	switch err {
 	case nil:
 		return new(pb.DeletePostResponse), nil
 	case errNotFound:
 		return nil, statusNotFound
+	case errVersionMismatch:
+		return nil, statusVersionMismatch
+	default:
+		return nil, errorStatus(err)
+	}
+}
+
+// RestorePost restores deleted post by ID
+func (s *Server) RestorePost(ctx context.Context, req *pb.RestorePostRequest) (*pb.RestorePostResponse, error) {
+	var v fieldViolations
+	uid := v.uid("uid", req.Uid)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	err := s.db.restorePost(ctx, uid)
+	switch err {
+	case nil:
+		return new(pb.RestorePostResponse), nil
+	case errNotFound:
+		return nil, statusNotFound
+	default:
+		return nil, errorStatus(err)
+	}
+}
+
+// PurgePost permanently removes post by ID
+func (s *Server) PurgePost(ctx context.Context, req *pb.PurgePostRequest) (*pb.PurgePostResponse, error) {
+	var v fieldViolations
+	uid := v.uid("uid", req.Uid)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	err := s.db.purgePost(ctx, uid)
+	switch err {
+	case nil:
+		return new(pb.PurgePostResponse), nil
+	case errNotFound:
+		return nil, statusNotFound
+	default:
+		return nil, errorStatus(err)
+	}
+}
+
+// ListPostRevisions returns revisions of post, latest first
+func (s *Server) ListPostRevisions(ctx context.Context, req *pb.ListPostRevisionsRequest) (*pb.ListPostRevisionsResponse, error) {
+	var v fieldViolations
+	uid := v.uid("uid", req.Uid)
+	pageSize := s.checkPage(&v, req.PageSize, req.PageNumber)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.db.listRevisions(ctx, uid, pageSize, req.PageNumber)
+	switch err {
+	case nil:
+	case errNotFound:
+		return nil, statusNotFound
+	default:
+		return nil, errorStatus(err)
+	}
+
+	res := new(pb.ListPostRevisionsResponse)
+	for _, revision := range revisions {
+		revisionResponse, err := revision.PostRevision()
+		if err != nil {
+			return nil, err
+		}
+
+		res.Revisions = append(res.Revisions, revisionResponse)
+	}
+
+	res.PageSize = pageSize
+	res.PageNumber = req.PageNumber
+
+	return res, nil
+}
+
+// GetPostRevision returns post revision by its number
+func (s *Server) GetPostRevision(ctx context.Context, req *pb.GetPostRevisionRequest) (*pb.PostRevision, error) {
+	var v fieldViolations
+	uid := v.uid("uid", req.Uid)
+	if req.Revision < 1 {
+		v.add("revision", "must be positive")
+	}
+
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	revision, err := s.db.getRevision(ctx, uid, req.Revision)
+	switch err {
+	case nil:
+		return revision.PostRevision()
+	case errNotFound:
+		return nil, statusRevisionNotFound
 	default:
-		return nil, internalError(err)
+		return nil, errorStatus(err)
 	}
 }
 
-// CheckPostExists checks if post with ID exists in DB
+// CheckPostExists checks if post with ID exists in DB and is not deleted
 func (s *Server) CheckPostExists(ctx context.Context, req *pb.CheckPostExistsRequest) (*pb.CheckPostExistsResponse, error) {
-	uid, err := uuid.Parse(req.Uid)
-	if err != nil {
-		return nil, statusInvalidUUID
+	var v fieldViolations
+	uid := v.uid("uid", req.Uid)
+	if err := v.err(); err != nil {
+		return nil, err
 	}
 
-	result, err := s.db.checkPostExists(uid)
+	result, err := s.db.checkPostExists(ctx, uid)
 	switch err {
 	case nil:
 		res := new(pb.CheckPostExistsResponse)
@@ -200,18 +512,19 @@ func (s *Server) CheckPostExists(ctx context.Context, req *pb.CheckPostExistsReq
 	case errNotFound:
 		return nil, statusNotFound
 	default:
-		return nil, internalError(err)
+		return nil, errorStatus(err)
 	}
 }
 
 // GetPostOwner returns post owner
 func (s *Server) GetPostOwner(ctx context.Context, req *pb.GetPostOwnerRequest) (*pb.GetPostOwnerResponse, error) {
-	uid, err := uuid.Parse(req.Uid)
-	if err != nil {
-		return nil, statusInvalidUUID
+	var v fieldViolations
+	uid := v.uid("uid", req.Uid)
+	if err := v.err(); err != nil {
+		return nil, err
 	}
 
-	result, err := s.db.getPostOwner(uid)
+	result, err := s.db.getPostOwner(ctx, uid)
 	switch err {
 	case nil:
 		res := new(pb.GetPostOwnerResponse)
@@ -220,6 +533,106 @@ func (s *Server) GetPostOwner(ctx context.Context, req *pb.GetPostOwnerRequest)
 	case errNotFound:
 		return nil, statusNotFound
 	default:
-		return nil, internalError(err)
+		return nil, errorStatus(err)
+	}
+}
+
+// Vote sets user vote for post, repeated vote in the same direction changes nothing
+func (s *Server) Vote(ctx context.Context, req *pb.VoteRequest) (*pb.VoteResponse, error) {
+	var v fieldViolations
+	postUID := v.uid("postUid", req.PostUid)
+	userUID := v.uid("userUid", req.UserUid)
+
+	var value int
+	switch req.Direction {
+	case pb.VoteDirection_UP:
+		value = 1
+	case pb.VoteDirection_DOWN:
+		value = -1
+	default:
+		v.add("direction", "must be UP or DOWN")
+	}
+
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	upvotes, downvotes, err := s.db.vote(ctx, postUID, userUID, value)
+	switch err {
+	case nil:
+		res := new(pb.VoteResponse)
+		res.Upvotes = upvotes
+		res.Downvotes = downvotes
+		res.Score = upvotes - downvotes
+		return res, nil
+	case errNotFound:
+		return nil, statusNotFound
+	default:
+		return nil, errorStatus(err)
 	}
 }
+
+// RemoveVote removes user vote for post
+func (s *Server) RemoveVote(ctx context.Context, req *pb.RemoveVoteRequest) (*pb.RemoveVoteResponse, error) {
+	var v fieldViolations
+	postUID := v.uid("postUid", req.PostUid)
+	userUID := v.uid("userUid", req.UserUid)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	upvotes, downvotes, err := s.db.removeVote(ctx, postUID, userUID)
+	switch err {
+	case nil:
+		res := new(pb.RemoveVoteResponse)
+		res.Upvotes = upvotes
+		res.Downvotes = downvotes
+		res.Score = upvotes - downvotes
+		return res, nil
+	case errNotFound:
+		return nil, statusNotFound
+	default:
+		return nil, errorStatus(err)
+	}
+}
+
+// SearchPosts returns posts which title or URL match query, most relevant first
+func (s *Server) SearchPosts(ctx context.Context, req *pb.SearchPostsRequest) (*pb.SearchPostsResponse, error) {
+	var v fieldViolations
+	if strings.TrimSpace(req.Query) == "" {
+		v.add("query", "is required")
+	} else {
+		v.text("query", req.Query, maxQueryLength)
+	}
+
+	categoryUID := v.optionalUID("categoryUid", req.CategoryUid)
+	userUID := v.optionalUID("userUid", req.UserUid)
+	pageSize := s.checkPage(&v, req.PageSize, req.PageNumber)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	results, err := s.db.searchPosts(ctx, req.Query, categoryUID, userUID, pageSize, req.PageNumber)
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+
+	res := new(pb.SearchPostsResponse)
+	for _, result := range results {
+		post, err := result.Post.SinglePost()
+		if err != nil {
+			return nil, err
+		}
+
+		searchResult := new(pb.SearchResult)
+		searchResult.Post = post
+		searchResult.Relevance = result.Relevance
+		searchResult.TitleHighlight = result.TitleHighlight
+		res.Results = append(res.Results, searchResult)
+	}
+
+	res.PageSize = pageSize
+	res.PageNumber = req.PageNumber
+
+	return res, nil
+}
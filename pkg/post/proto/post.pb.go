@@ -7,6 +7,7 @@ import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
 import timestamp "github.com/golang/protobuf/ptypes/timestamp"
+import field_mask "google.golang.org/genproto/protobuf/field_mask"
 
 import (
 	context "golang.org/x/net/context"
@@ -24,19 +25,130 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+type SortOrder int32
+
+const (
+	SortOrder_NEW           SortOrder = 0
+	SortOrder_HOT           SortOrder = 1
+	SortOrder_TOP           SortOrder = 2
+	SortOrder_CONTROVERSIAL SortOrder = 3
+)
+
+var SortOrder_name = map[int32]string{
+	0: "NEW",
+	1: "HOT",
+	2: "TOP",
+	3: "CONTROVERSIAL",
+}
+var SortOrder_value = map[string]int32{
+	"NEW":           0,
+	"HOT":           1,
+	"TOP":           2,
+	"CONTROVERSIAL": 3,
+}
+
+func (x SortOrder) String() string {
+	return proto.EnumName(SortOrder_name, int32(x))
+}
+func (SortOrder) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{0}
+}
+
+type TimeWindow int32
+
+const (
+	TimeWindow_ALL   TimeWindow = 0
+	TimeWindow_DAY   TimeWindow = 1
+	TimeWindow_WEEK  TimeWindow = 2
+	TimeWindow_MONTH TimeWindow = 3
+)
+
+var TimeWindow_name = map[int32]string{
+	0: "ALL",
+	1: "DAY",
+	2: "WEEK",
+	3: "MONTH",
+}
+var TimeWindow_value = map[string]int32{
+	"ALL":   0,
+	"DAY":   1,
+	"WEEK":  2,
+	"MONTH": 3,
+}
+
+func (x TimeWindow) String() string {
+	return proto.EnumName(TimeWindow_name, int32(x))
+}
+func (TimeWindow) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{1}
+}
+
+type PostKind int32
+
+const (
+	PostKind_LINK PostKind = 0
+	PostKind_TEXT PostKind = 1
+)
+
+var PostKind_name = map[int32]string{
+	0: "LINK",
+	1: "TEXT",
+}
+var PostKind_value = map[string]int32{
+	"LINK": 0,
+	"TEXT": 1,
+}
+
+func (x PostKind) String() string {
+	return proto.EnumName(PostKind_name, int32(x))
+}
+func (PostKind) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{2}
+}
+
+type VoteDirection int32
+
+const (
+	VoteDirection_NONE VoteDirection = 0
+	VoteDirection_UP   VoteDirection = 1
+	VoteDirection_DOWN VoteDirection = 2
+)
+
+var VoteDirection_name = map[int32]string{
+	0: "NONE",
+	1: "UP",
+	2: "DOWN",
+}
+var VoteDirection_value = map[string]int32{
+	"NONE": 0,
+	"UP":   1,
+	"DOWN": 2,
+}
+
+func (x VoteDirection) String() string {
+	return proto.EnumName(VoteDirection_name, int32(x))
+}
+func (VoteDirection) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{3}
+}
+
 type ListPostsRequest struct {
-	PageSize             int32    `protobuf:"varint,1,opt,name=pageSize,proto3" json:"pageSize,omitempty"`
-	PageNumber           int32    `protobuf:"varint,2,opt,name=pageNumber,proto3" json:"pageNumber,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	PageSize             int32      `protobuf:"varint,1,opt,name=pageSize,proto3" json:"pageSize,omitempty"`
+	PageNumber           int32      `protobuf:"varint,2,opt,name=pageNumber,proto3" json:"pageNumber,omitempty"`
+	PageToken            string     `protobuf:"bytes,3,opt,name=pageToken,proto3" json:"pageToken,omitempty"`
+	Sort                 SortOrder  `protobuf:"varint,4,opt,name=sort,proto3,enum=post.SortOrder" json:"sort,omitempty"`
+	Window               TimeWindow `protobuf:"varint,5,opt,name=window,proto3,enum=post.TimeWindow" json:"window,omitempty"`
+	OmitBody             bool       `protobuf:"varint,6,opt,name=omitBody,proto3" json:"omitBody,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
 }
 
 func (m *ListPostsRequest) Reset()         { *m = ListPostsRequest{} }
 func (m *ListPostsRequest) String() string { return proto.CompactTextString(m) }
 func (*ListPostsRequest) ProtoMessage()    {}
 func (*ListPostsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_post_eedb255c562f0a82, []int{0}
+	return fileDescriptor_post_125dfb2770aac849, []int{0}
 }
 func (m *ListPostsRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ListPostsRequest.Unmarshal(m, b)
@@ -70,20 +182,52 @@ func (m *ListPostsRequest) GetPageNumber() int32 {
 	return 0
 }
 
+func (m *ListPostsRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+func (m *ListPostsRequest) GetSort() SortOrder {
+	if m != nil {
+		return m.Sort
+	}
+	return SortOrder_NEW
+}
+
+func (m *ListPostsRequest) GetWindow() TimeWindow {
+	if m != nil {
+		return m.Window
+	}
+	return TimeWindow_ALL
+}
+
+func (m *ListPostsRequest) GetOmitBody() bool {
+	if m != nil {
+		return m.OmitBody
+	}
+	return false
+}
+
 type ListPostsByCategoryRequest struct {
-	CategoryUid          string   `protobuf:"bytes,1,opt,name=categoryUid,proto3" json:"categoryUid,omitempty"`
-	PageSize             int32    `protobuf:"varint,2,opt,name=pageSize,proto3" json:"pageSize,omitempty"`
-	PageNumber           int32    `protobuf:"varint,3,opt,name=pageNumber,proto3" json:"pageNumber,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	CategoryUid          string     `protobuf:"bytes,1,opt,name=categoryUid,proto3" json:"categoryUid,omitempty"`
+	PageSize             int32      `protobuf:"varint,2,opt,name=pageSize,proto3" json:"pageSize,omitempty"`
+	PageNumber           int32      `protobuf:"varint,3,opt,name=pageNumber,proto3" json:"pageNumber,omitempty"`
+	PageToken            string     `protobuf:"bytes,4,opt,name=pageToken,proto3" json:"pageToken,omitempty"`
+	Sort                 SortOrder  `protobuf:"varint,5,opt,name=sort,proto3,enum=post.SortOrder" json:"sort,omitempty"`
+	Window               TimeWindow `protobuf:"varint,6,opt,name=window,proto3,enum=post.TimeWindow" json:"window,omitempty"`
+	OmitBody             bool       `protobuf:"varint,7,opt,name=omitBody,proto3" json:"omitBody,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
 }
 
 func (m *ListPostsByCategoryRequest) Reset()         { *m = ListPostsByCategoryRequest{} }
 func (m *ListPostsByCategoryRequest) String() string { return proto.CompactTextString(m) }
 func (*ListPostsByCategoryRequest) ProtoMessage()    {}
 func (*ListPostsByCategoryRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_post_eedb255c562f0a82, []int{1}
+	return fileDescriptor_post_125dfb2770aac849, []int{1}
 }
 func (m *ListPostsByCategoryRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ListPostsByCategoryRequest.Unmarshal(m, b)
@@ -124,10 +268,39 @@ func (m *ListPostsByCategoryRequest) GetPageNumber() int32 {
 	return 0
 }
 
+func (m *ListPostsByCategoryRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+func (m *ListPostsByCategoryRequest) GetSort() SortOrder {
+	if m != nil {
+		return m.Sort
+	}
+	return SortOrder_NEW
+}
+
+func (m *ListPostsByCategoryRequest) GetWindow() TimeWindow {
+	if m != nil {
+		return m.Window
+	}
+	return TimeWindow_ALL
+}
+
+func (m *ListPostsByCategoryRequest) GetOmitBody() bool {
+	if m != nil {
+		return m.OmitBody
+	}
+	return false
+}
+
 type ListPostsResponse struct {
 	Posts                []*SinglePost `protobuf:"bytes,1,rep,name=posts,proto3" json:"posts,omitempty"`
 	PageSize             int32         `protobuf:"varint,2,opt,name=pageSize,proto3" json:"pageSize,omitempty"`
 	PageNumber           int32         `protobuf:"varint,3,opt,name=pageNumber,proto3" json:"pageNumber,omitempty"`
+	NextPageToken        string        `protobuf:"bytes,4,opt,name=nextPageToken,proto3" json:"nextPageToken,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
 	XXX_unrecognized     []byte        `json:"-"`
 	XXX_sizecache        int32         `json:"-"`
@@ -137,7 +310,7 @@ func (m *ListPostsResponse) Reset()         { *m = ListPostsResponse{} }
 func (m *ListPostsResponse) String() string { return proto.CompactTextString(m) }
 func (*ListPostsResponse) ProtoMessage()    {}
 func (*ListPostsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_post_eedb255c562f0a82, []int{2}
+	return fileDescriptor_post_125dfb2770aac849, []int{2}
 }
 func (m *ListPostsResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ListPostsResponse.Unmarshal(m, b)
@@ -178,8 +351,16 @@ func (m *ListPostsResponse) GetPageNumber() int32 {
 	return 0
 }
 
+func (m *ListPostsResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 type GetPostRequest struct {
 	Uid                  string   `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
+	IncludeDeleted       bool     `protobuf:"varint,2,opt,name=includeDeleted,proto3" json:"includeDeleted,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -189,7 +370,7 @@ func (m *GetPostRequest) Reset()         { *m = GetPostRequest{} }
 func (m *GetPostRequest) String() string { return proto.CompactTextString(m) }
 func (*GetPostRequest) ProtoMessage()    {}
 func (*GetPostRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_post_eedb255c562f0a82, []int{3}
+	return fileDescriptor_post_125dfb2770aac849, []int{3}
 }
 func (m *GetPostRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetPostRequest.Unmarshal(m, b)
@@ -216,6 +397,13 @@ func (m *GetPostRequest) GetUid() string {
 	return ""
 }
 
+func (m *GetPostRequest) GetIncludeDeleted() bool {
+	if m != nil {
+		return m.IncludeDeleted
+	}
+	return false
+}
+
 type SinglePost struct {
 	Uid                  string               `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
 	UserUid              string               `protobuf:"bytes,2,opt,name=userUid,proto3" json:"userUid,omitempty"`
@@ -224,6 +412,15 @@ type SinglePost struct {
 	Url                  string               `protobuf:"bytes,5,opt,name=url,proto3" json:"url,omitempty"`
 	CreatedAt            *timestamp.Timestamp `protobuf:"bytes,6,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
 	ModifiedAt           *timestamp.Timestamp `protobuf:"bytes,7,opt,name=modifiedAt,proto3" json:"modifiedAt,omitempty"`
+	Upvotes              int32                `protobuf:"varint,8,opt,name=upvotes,proto3" json:"upvotes,omitempty"`
+	Downvotes            int32                `protobuf:"varint,9,opt,name=downvotes,proto3" json:"downvotes,omitempty"`
+	Score                int32                `protobuf:"varint,10,opt,name=score,proto3" json:"score,omitempty"`
+	Kind                 PostKind             `protobuf:"varint,11,opt,name=kind,proto3,enum=post.PostKind" json:"kind,omitempty"`
+	Body                 string               `protobuf:"bytes,12,opt,name=body,proto3" json:"body,omitempty"`
+	DeletedAt            *timestamp.Timestamp `protobuf:"bytes,13,opt,name=deletedAt,proto3" json:"deletedAt,omitempty"`
+	Revision             int32                `protobuf:"varint,14,opt,name=revision,proto3" json:"revision,omitempty"`
+	Edited               bool                 `protobuf:"varint,15,opt,name=edited,proto3" json:"edited,omitempty"`
+	Version              int64                `protobuf:"varint,16,opt,name=version,proto3" json:"version,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
 	XXX_unrecognized     []byte               `json:"-"`
 	XXX_sizecache        int32                `json:"-"`
@@ -233,7 +430,7 @@ func (m *SinglePost) Reset()         { *m = SinglePost{} }
 func (m *SinglePost) String() string { return proto.CompactTextString(m) }
 func (*SinglePost) ProtoMessage()    {}
 func (*SinglePost) Descriptor() ([]byte, []int) {
-	return fileDescriptor_post_eedb255c562f0a82, []int{4}
+	return fileDescriptor_post_125dfb2770aac849, []int{4}
 }
 func (m *SinglePost) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SinglePost.Unmarshal(m, b)
@@ -302,11 +499,77 @@ func (m *SinglePost) GetModifiedAt() *timestamp.Timestamp {
 	return nil
 }
 
+func (m *SinglePost) GetUpvotes() int32 {
+	if m != nil {
+		return m.Upvotes
+	}
+	return 0
+}
+
+func (m *SinglePost) GetDownvotes() int32 {
+	if m != nil {
+		return m.Downvotes
+	}
+	return 0
+}
+
+func (m *SinglePost) GetScore() int32 {
+	if m != nil {
+		return m.Score
+	}
+	return 0
+}
+
+func (m *SinglePost) GetKind() PostKind {
+	if m != nil {
+		return m.Kind
+	}
+	return PostKind_LINK
+}
+
+func (m *SinglePost) GetBody() string {
+	if m != nil {
+		return m.Body
+	}
+	return ""
+}
+
+func (m *SinglePost) GetDeletedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.DeletedAt
+	}
+	return nil
+}
+
+func (m *SinglePost) GetRevision() int32 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+func (m *SinglePost) GetEdited() bool {
+	if m != nil {
+		return m.Edited
+	}
+	return false
+}
+
+func (m *SinglePost) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
 type CreatePostRequest struct {
 	Title                string   `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
 	Url                  string   `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
 	UserUid              string   `protobuf:"bytes,3,opt,name=userUid,proto3" json:"userUid,omitempty"`
 	CategoryUid          string   `protobuf:"bytes,4,opt,name=categoryUid,proto3" json:"categoryUid,omitempty"`
+	Kind                 PostKind `protobuf:"varint,5,opt,name=kind,proto3,enum=post.PostKind" json:"kind,omitempty"`
+	Body                 string   `protobuf:"bytes,6,opt,name=body,proto3" json:"body,omitempty"`
+	IdempotencyKey       string   `protobuf:"bytes,7,opt,name=idempotencyKey,proto3" json:"idempotencyKey,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -316,7 +579,7 @@ func (m *CreatePostRequest) Reset()         { *m = CreatePostRequest{} }
 func (m *CreatePostRequest) String() string { return proto.CompactTextString(m) }
 func (*CreatePostRequest) ProtoMessage()    {}
 func (*CreatePostRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_post_eedb255c562f0a82, []int{5}
+	return fileDescriptor_post_125dfb2770aac849, []int{5}
 }
 func (m *CreatePostRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreatePostRequest.Unmarshal(m, b)
@@ -364,20 +627,44 @@ func (m *CreatePostRequest) GetCategoryUid() string {
 	return ""
 }
 
+func (m *CreatePostRequest) GetKind() PostKind {
+	if m != nil {
+		return m.Kind
+	}
+	return PostKind_LINK
+}
+
+func (m *CreatePostRequest) GetBody() string {
+	if m != nil {
+		return m.Body
+	}
+	return ""
+}
+
+func (m *CreatePostRequest) GetIdempotencyKey() string {
+	if m != nil {
+		return m.IdempotencyKey
+	}
+	return ""
+}
+
 type UpdatePostRequest struct {
-	Uid                  string   `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
-	Title                string   `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	Url                  string   `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Uid                  string                `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
+	Title                string                `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Url                  string                `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	Body                 string                `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	ExpectedVersion      int64                 `protobuf:"varint,5,opt,name=expectedVersion,proto3" json:"expectedVersion,omitempty"`
+	UpdateMask           *field_mask.FieldMask `protobuf:"bytes,6,opt,name=updateMask,proto3" json:"updateMask,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
 }
 
 func (m *UpdatePostRequest) Reset()         { *m = UpdatePostRequest{} }
 func (m *UpdatePostRequest) String() string { return proto.CompactTextString(m) }
 func (*UpdatePostRequest) ProtoMessage()    {}
 func (*UpdatePostRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_post_eedb255c562f0a82, []int{6}
+	return fileDescriptor_post_125dfb2770aac849, []int{6}
 }
 func (m *UpdatePostRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UpdatePostRequest.Unmarshal(m, b)
@@ -418,7 +705,29 @@ func (m *UpdatePostRequest) GetUrl() string {
 	return ""
 }
 
+func (m *UpdatePostRequest) GetBody() string {
+	if m != nil {
+		return m.Body
+	}
+	return ""
+}
+
+func (m *UpdatePostRequest) GetExpectedVersion() int64 {
+	if m != nil {
+		return m.ExpectedVersion
+	}
+	return 0
+}
+
+func (m *UpdatePostRequest) GetUpdateMask() *field_mask.FieldMask {
+	if m != nil {
+		return m.UpdateMask
+	}
+	return nil
+}
+
 type UpdatePostResponse struct {
+	Version              int64    `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -428,7 +737,7 @@ func (m *UpdatePostResponse) Reset()         { *m = UpdatePostResponse{} }
 func (m *UpdatePostResponse) String() string { return proto.CompactTextString(m) }
 func (*UpdatePostResponse) ProtoMessage()    {}
 func (*UpdatePostResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_post_eedb255c562f0a82, []int{7}
+	return fileDescriptor_post_125dfb2770aac849, []int{7}
 }
 func (m *UpdatePostResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UpdatePostResponse.Unmarshal(m, b)
@@ -448,8 +757,16 @@ func (m *UpdatePostResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_UpdatePostResponse proto.InternalMessageInfo
 
+func (m *UpdatePostResponse) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
 type DeletePostRequest struct {
 	Uid                  string   `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
+	ExpectedVersion      int64    `protobuf:"varint,2,opt,name=expectedVersion,proto3" json:"expectedVersion,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -459,7 +776,7 @@ func (m *DeletePostRequest) Reset()         { *m = DeletePostRequest{} }
 func (m *DeletePostRequest) String() string { return proto.CompactTextString(m) }
 func (*DeletePostRequest) ProtoMessage()    {}
 func (*DeletePostRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_post_eedb255c562f0a82, []int{8}
+	return fileDescriptor_post_125dfb2770aac849, []int{8}
 }
 func (m *DeletePostRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DeletePostRequest.Unmarshal(m, b)
@@ -486,6 +803,13 @@ func (m *DeletePostRequest) GetUid() string {
 	return ""
 }
 
+func (m *DeletePostRequest) GetExpectedVersion() int64 {
+	if m != nil {
+		return m.ExpectedVersion
+	}
+	return 0
+}
+
 type DeletePostResponse struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -496,7 +820,7 @@ func (m *DeletePostResponse) Reset()         { *m = DeletePostResponse{} }
 func (m *DeletePostResponse) String() string { return proto.CompactTextString(m) }
 func (*DeletePostResponse) ProtoMessage()    {}
 func (*DeletePostResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_post_eedb255c562f0a82, []int{9}
+	return fileDescriptor_post_125dfb2770aac849, []int{9}
 }
 func (m *DeletePostResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DeletePostResponse.Unmarshal(m, b)
@@ -516,158 +840,912 @@ func (m *DeletePostResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_DeletePostResponse proto.InternalMessageInfo
 
-type CheckPostExistsRequest struct {
+type RestorePostRequest struct {
 	Uid                  string   `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *CheckPostExistsRequest) Reset()         { *m = CheckPostExistsRequest{} }
-func (m *CheckPostExistsRequest) String() string { return proto.CompactTextString(m) }
-func (*CheckPostExistsRequest) ProtoMessage()    {}
-func (*CheckPostExistsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_post_eedb255c562f0a82, []int{10}
+func (m *RestorePostRequest) Reset()         { *m = RestorePostRequest{} }
+func (m *RestorePostRequest) String() string { return proto.CompactTextString(m) }
+func (*RestorePostRequest) ProtoMessage()    {}
+func (*RestorePostRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{10}
 }
-func (m *CheckPostExistsRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_CheckPostExistsRequest.Unmarshal(m, b)
+func (m *RestorePostRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RestorePostRequest.Unmarshal(m, b)
 }
-func (m *CheckPostExistsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_CheckPostExistsRequest.Marshal(b, m, deterministic)
+func (m *RestorePostRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RestorePostRequest.Marshal(b, m, deterministic)
 }
-func (dst *CheckPostExistsRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_CheckPostExistsRequest.Merge(dst, src)
+func (dst *RestorePostRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RestorePostRequest.Merge(dst, src)
 }
-func (m *CheckPostExistsRequest) XXX_Size() int {
-	return xxx_messageInfo_CheckPostExistsRequest.Size(m)
+func (m *RestorePostRequest) XXX_Size() int {
+	return xxx_messageInfo_RestorePostRequest.Size(m)
 }
-func (m *CheckPostExistsRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_CheckPostExistsRequest.DiscardUnknown(m)
+func (m *RestorePostRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RestorePostRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_CheckPostExistsRequest proto.InternalMessageInfo
+var xxx_messageInfo_RestorePostRequest proto.InternalMessageInfo
 
-func (m *CheckPostExistsRequest) GetUid() string {
+func (m *RestorePostRequest) GetUid() string {
 	if m != nil {
 		return m.Uid
 	}
 	return ""
 }
 
-type CheckPostExistsResponse struct {
-	Exists               bool     `protobuf:"varint,1,opt,name=exists,proto3" json:"exists,omitempty"`
+type RestorePostResponse struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *CheckPostExistsResponse) Reset()         { *m = CheckPostExistsResponse{} }
-func (m *CheckPostExistsResponse) String() string { return proto.CompactTextString(m) }
-func (*CheckPostExistsResponse) ProtoMessage()    {}
-func (*CheckPostExistsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_post_eedb255c562f0a82, []int{11}
+func (m *RestorePostResponse) Reset()         { *m = RestorePostResponse{} }
+func (m *RestorePostResponse) String() string { return proto.CompactTextString(m) }
+func (*RestorePostResponse) ProtoMessage()    {}
+func (*RestorePostResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{11}
 }
-func (m *CheckPostExistsResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_CheckPostExistsResponse.Unmarshal(m, b)
+func (m *RestorePostResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RestorePostResponse.Unmarshal(m, b)
 }
-func (m *CheckPostExistsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_CheckPostExistsResponse.Marshal(b, m, deterministic)
+func (m *RestorePostResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RestorePostResponse.Marshal(b, m, deterministic)
 }
-func (dst *CheckPostExistsResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_CheckPostExistsResponse.Merge(dst, src)
+func (dst *RestorePostResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RestorePostResponse.Merge(dst, src)
 }
-func (m *CheckPostExistsResponse) XXX_Size() int {
-	return xxx_messageInfo_CheckPostExistsResponse.Size(m)
+func (m *RestorePostResponse) XXX_Size() int {
+	return xxx_messageInfo_RestorePostResponse.Size(m)
 }
-func (m *CheckPostExistsResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_CheckPostExistsResponse.DiscardUnknown(m)
+func (m *RestorePostResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RestorePostResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_CheckPostExistsResponse proto.InternalMessageInfo
-
-func (m *CheckPostExistsResponse) GetExists() bool {
-	if m != nil {
-		return m.Exists
-	}
-	return false
-}
+var xxx_messageInfo_RestorePostResponse proto.InternalMessageInfo
 
-type GetPostOwnerRequest struct {
+type PurgePostRequest struct {
 	Uid                  string   `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *GetPostOwnerRequest) Reset()         { *m = GetPostOwnerRequest{} }
-func (m *GetPostOwnerRequest) String() string { return proto.CompactTextString(m) }
-func (*GetPostOwnerRequest) ProtoMessage()    {}
-func (*GetPostOwnerRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_post_eedb255c562f0a82, []int{12}
+func (m *PurgePostRequest) Reset()         { *m = PurgePostRequest{} }
+func (m *PurgePostRequest) String() string { return proto.CompactTextString(m) }
+func (*PurgePostRequest) ProtoMessage()    {}
+func (*PurgePostRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{12}
 }
-func (m *GetPostOwnerRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GetPostOwnerRequest.Unmarshal(m, b)
+func (m *PurgePostRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PurgePostRequest.Unmarshal(m, b)
 }
-func (m *GetPostOwnerRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GetPostOwnerRequest.Marshal(b, m, deterministic)
+func (m *PurgePostRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PurgePostRequest.Marshal(b, m, deterministic)
 }
-func (dst *GetPostOwnerRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetPostOwnerRequest.Merge(dst, src)
+func (dst *PurgePostRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PurgePostRequest.Merge(dst, src)
 }
-func (m *GetPostOwnerRequest) XXX_Size() int {
-	return xxx_messageInfo_GetPostOwnerRequest.Size(m)
+func (m *PurgePostRequest) XXX_Size() int {
+	return xxx_messageInfo_PurgePostRequest.Size(m)
 }
-func (m *GetPostOwnerRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetPostOwnerRequest.DiscardUnknown(m)
+func (m *PurgePostRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PurgePostRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_GetPostOwnerRequest proto.InternalMessageInfo
+var xxx_messageInfo_PurgePostRequest proto.InternalMessageInfo
 
-func (m *GetPostOwnerRequest) GetUid() string {
+func (m *PurgePostRequest) GetUid() string {
 	if m != nil {
 		return m.Uid
 	}
 	return ""
 }
 
-type GetPostOwnerResponse struct {
-	OwnerUid             string   `protobuf:"bytes,1,opt,name=ownerUid,proto3" json:"ownerUid,omitempty"`
+type PurgePostResponse struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *GetPostOwnerResponse) Reset()         { *m = GetPostOwnerResponse{} }
-func (m *GetPostOwnerResponse) String() string { return proto.CompactTextString(m) }
-func (*GetPostOwnerResponse) ProtoMessage()    {}
-func (*GetPostOwnerResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_post_eedb255c562f0a82, []int{13}
+func (m *PurgePostResponse) Reset()         { *m = PurgePostResponse{} }
+func (m *PurgePostResponse) String() string { return proto.CompactTextString(m) }
+func (*PurgePostResponse) ProtoMessage()    {}
+func (*PurgePostResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{13}
 }
-func (m *GetPostOwnerResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GetPostOwnerResponse.Unmarshal(m, b)
+func (m *PurgePostResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PurgePostResponse.Unmarshal(m, b)
 }
-func (m *GetPostOwnerResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GetPostOwnerResponse.Marshal(b, m, deterministic)
+func (m *PurgePostResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PurgePostResponse.Marshal(b, m, deterministic)
 }
-func (dst *GetPostOwnerResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetPostOwnerResponse.Merge(dst, src)
+func (dst *PurgePostResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PurgePostResponse.Merge(dst, src)
 }
-func (m *GetPostOwnerResponse) XXX_Size() int {
-	return xxx_messageInfo_GetPostOwnerResponse.Size(m)
+func (m *PurgePostResponse) XXX_Size() int {
+	return xxx_messageInfo_PurgePostResponse.Size(m)
 }
-func (m *GetPostOwnerResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetPostOwnerResponse.DiscardUnknown(m)
+func (m *PurgePostResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PurgePostResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_GetPostOwnerResponse proto.InternalMessageInfo
+var xxx_messageInfo_PurgePostResponse proto.InternalMessageInfo
 
-func (m *GetPostOwnerResponse) GetOwnerUid() string {
-	if m != nil {
-		return m.OwnerUid
-	}
+type PostRevision struct {
+	PostUid              string               `protobuf:"bytes,1,opt,name=postUid,proto3" json:"postUid,omitempty"`
+	Revision             int32                `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"`
+	Title                string               `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Url                  string               `protobuf:"bytes,4,opt,name=url,proto3" json:"url,omitempty"`
+	Body                 string               `protobuf:"bytes,5,opt,name=body,proto3" json:"body,omitempty"`
+	CreatedAt            *timestamp.Timestamp `protobuf:"bytes,6,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *PostRevision) Reset()         { *m = PostRevision{} }
+func (m *PostRevision) String() string { return proto.CompactTextString(m) }
+func (*PostRevision) ProtoMessage()    {}
+func (*PostRevision) Descriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{14}
+}
+func (m *PostRevision) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PostRevision.Unmarshal(m, b)
+}
+func (m *PostRevision) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PostRevision.Marshal(b, m, deterministic)
+}
+func (dst *PostRevision) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PostRevision.Merge(dst, src)
+}
+func (m *PostRevision) XXX_Size() int {
+	return xxx_messageInfo_PostRevision.Size(m)
+}
+func (m *PostRevision) XXX_DiscardUnknown() {
+	xxx_messageInfo_PostRevision.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PostRevision proto.InternalMessageInfo
+
+func (m *PostRevision) GetPostUid() string {
+	if m != nil {
+		return m.PostUid
+	}
+	return ""
+}
+
+func (m *PostRevision) GetRevision() int32 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+func (m *PostRevision) GetTitle() string {
+	if m != nil {
+		return m.Title
+	}
+	return ""
+}
+
+func (m *PostRevision) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *PostRevision) GetBody() string {
+	if m != nil {
+		return m.Body
+	}
+	return ""
+}
+
+func (m *PostRevision) GetCreatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return nil
+}
+
+type ListPostRevisionsRequest struct {
+	Uid                  string   `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
+	PageSize             int32    `protobuf:"varint,2,opt,name=pageSize,proto3" json:"pageSize,omitempty"`
+	PageNumber           int32    `protobuf:"varint,3,opt,name=pageNumber,proto3" json:"pageNumber,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListPostRevisionsRequest) Reset()         { *m = ListPostRevisionsRequest{} }
+func (m *ListPostRevisionsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListPostRevisionsRequest) ProtoMessage()    {}
+func (*ListPostRevisionsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{15}
+}
+func (m *ListPostRevisionsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListPostRevisionsRequest.Unmarshal(m, b)
+}
+func (m *ListPostRevisionsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListPostRevisionsRequest.Marshal(b, m, deterministic)
+}
+func (dst *ListPostRevisionsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListPostRevisionsRequest.Merge(dst, src)
+}
+func (m *ListPostRevisionsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListPostRevisionsRequest.Size(m)
+}
+func (m *ListPostRevisionsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListPostRevisionsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListPostRevisionsRequest proto.InternalMessageInfo
+
+func (m *ListPostRevisionsRequest) GetUid() string {
+	if m != nil {
+		return m.Uid
+	}
+	return ""
+}
+
+func (m *ListPostRevisionsRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *ListPostRevisionsRequest) GetPageNumber() int32 {
+	if m != nil {
+		return m.PageNumber
+	}
+	return 0
+}
+
+type ListPostRevisionsResponse struct {
+	Revisions            []*PostRevision `protobuf:"bytes,1,rep,name=revisions,proto3" json:"revisions,omitempty"`
+	PageSize             int32           `protobuf:"varint,2,opt,name=pageSize,proto3" json:"pageSize,omitempty"`
+	PageNumber           int32           `protobuf:"varint,3,opt,name=pageNumber,proto3" json:"pageNumber,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *ListPostRevisionsResponse) Reset()         { *m = ListPostRevisionsResponse{} }
+func (m *ListPostRevisionsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListPostRevisionsResponse) ProtoMessage()    {}
+func (*ListPostRevisionsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{16}
+}
+func (m *ListPostRevisionsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListPostRevisionsResponse.Unmarshal(m, b)
+}
+func (m *ListPostRevisionsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListPostRevisionsResponse.Marshal(b, m, deterministic)
+}
+func (dst *ListPostRevisionsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListPostRevisionsResponse.Merge(dst, src)
+}
+func (m *ListPostRevisionsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListPostRevisionsResponse.Size(m)
+}
+func (m *ListPostRevisionsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListPostRevisionsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListPostRevisionsResponse proto.InternalMessageInfo
+
+func (m *ListPostRevisionsResponse) GetRevisions() []*PostRevision {
+	if m != nil {
+		return m.Revisions
+	}
+	return nil
+}
+
+func (m *ListPostRevisionsResponse) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *ListPostRevisionsResponse) GetPageNumber() int32 {
+	if m != nil {
+		return m.PageNumber
+	}
+	return 0
+}
+
+type GetPostRevisionRequest struct {
+	Uid                  string   `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
+	Revision             int32    `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetPostRevisionRequest) Reset()         { *m = GetPostRevisionRequest{} }
+func (m *GetPostRevisionRequest) String() string { return proto.CompactTextString(m) }
+func (*GetPostRevisionRequest) ProtoMessage()    {}
+func (*GetPostRevisionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{17}
+}
+func (m *GetPostRevisionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetPostRevisionRequest.Unmarshal(m, b)
+}
+func (m *GetPostRevisionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetPostRevisionRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetPostRevisionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetPostRevisionRequest.Merge(dst, src)
+}
+func (m *GetPostRevisionRequest) XXX_Size() int {
+	return xxx_messageInfo_GetPostRevisionRequest.Size(m)
+}
+func (m *GetPostRevisionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetPostRevisionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetPostRevisionRequest proto.InternalMessageInfo
+
+func (m *GetPostRevisionRequest) GetUid() string {
+	if m != nil {
+		return m.Uid
+	}
+	return ""
+}
+
+func (m *GetPostRevisionRequest) GetRevision() int32 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+type CheckPostExistsRequest struct {
+	Uid                  string   `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CheckPostExistsRequest) Reset()         { *m = CheckPostExistsRequest{} }
+func (m *CheckPostExistsRequest) String() string { return proto.CompactTextString(m) }
+func (*CheckPostExistsRequest) ProtoMessage()    {}
+func (*CheckPostExistsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{18}
+}
+func (m *CheckPostExistsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CheckPostExistsRequest.Unmarshal(m, b)
+}
+func (m *CheckPostExistsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CheckPostExistsRequest.Marshal(b, m, deterministic)
+}
+func (dst *CheckPostExistsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CheckPostExistsRequest.Merge(dst, src)
+}
+func (m *CheckPostExistsRequest) XXX_Size() int {
+	return xxx_messageInfo_CheckPostExistsRequest.Size(m)
+}
+func (m *CheckPostExistsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CheckPostExistsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CheckPostExistsRequest proto.InternalMessageInfo
+
+func (m *CheckPostExistsRequest) GetUid() string {
+	if m != nil {
+		return m.Uid
+	}
+	return ""
+}
+
+type CheckPostExistsResponse struct {
+	Exists               bool     `protobuf:"varint,1,opt,name=exists,proto3" json:"exists,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CheckPostExistsResponse) Reset()         { *m = CheckPostExistsResponse{} }
+func (m *CheckPostExistsResponse) String() string { return proto.CompactTextString(m) }
+func (*CheckPostExistsResponse) ProtoMessage()    {}
+func (*CheckPostExistsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{19}
+}
+func (m *CheckPostExistsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CheckPostExistsResponse.Unmarshal(m, b)
+}
+func (m *CheckPostExistsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CheckPostExistsResponse.Marshal(b, m, deterministic)
+}
+func (dst *CheckPostExistsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CheckPostExistsResponse.Merge(dst, src)
+}
+func (m *CheckPostExistsResponse) XXX_Size() int {
+	return xxx_messageInfo_CheckPostExistsResponse.Size(m)
+}
+func (m *CheckPostExistsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CheckPostExistsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CheckPostExistsResponse proto.InternalMessageInfo
+
+func (m *CheckPostExistsResponse) GetExists() bool {
+	if m != nil {
+		return m.Exists
+	}
+	return false
+}
+
+type GetPostOwnerRequest struct {
+	Uid                  string   `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetPostOwnerRequest) Reset()         { *m = GetPostOwnerRequest{} }
+func (m *GetPostOwnerRequest) String() string { return proto.CompactTextString(m) }
+func (*GetPostOwnerRequest) ProtoMessage()    {}
+func (*GetPostOwnerRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{20}
+}
+func (m *GetPostOwnerRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetPostOwnerRequest.Unmarshal(m, b)
+}
+func (m *GetPostOwnerRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetPostOwnerRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetPostOwnerRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetPostOwnerRequest.Merge(dst, src)
+}
+func (m *GetPostOwnerRequest) XXX_Size() int {
+	return xxx_messageInfo_GetPostOwnerRequest.Size(m)
+}
+func (m *GetPostOwnerRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetPostOwnerRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetPostOwnerRequest proto.InternalMessageInfo
+
+func (m *GetPostOwnerRequest) GetUid() string {
+	if m != nil {
+		return m.Uid
+	}
+	return ""
+}
+
+type GetPostOwnerResponse struct {
+	OwnerUid             string   `protobuf:"bytes,1,opt,name=ownerUid,proto3" json:"ownerUid,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetPostOwnerResponse) Reset()         { *m = GetPostOwnerResponse{} }
+func (m *GetPostOwnerResponse) String() string { return proto.CompactTextString(m) }
+func (*GetPostOwnerResponse) ProtoMessage()    {}
+func (*GetPostOwnerResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{21}
+}
+func (m *GetPostOwnerResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetPostOwnerResponse.Unmarshal(m, b)
+}
+func (m *GetPostOwnerResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetPostOwnerResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetPostOwnerResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetPostOwnerResponse.Merge(dst, src)
+}
+func (m *GetPostOwnerResponse) XXX_Size() int {
+	return xxx_messageInfo_GetPostOwnerResponse.Size(m)
+}
+func (m *GetPostOwnerResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetPostOwnerResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetPostOwnerResponse proto.InternalMessageInfo
+
+func (m *GetPostOwnerResponse) GetOwnerUid() string {
+	if m != nil {
+		return m.OwnerUid
+	}
+	return ""
+}
+
+type VoteRequest struct {
+	PostUid              string        `protobuf:"bytes,1,opt,name=postUid,proto3" json:"postUid,omitempty"`
+	UserUid              string        `protobuf:"bytes,2,opt,name=userUid,proto3" json:"userUid,omitempty"`
+	Direction            VoteDirection `protobuf:"varint,3,opt,name=direction,proto3,enum=post.VoteDirection" json:"direction,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *VoteRequest) Reset()         { *m = VoteRequest{} }
+func (m *VoteRequest) String() string { return proto.CompactTextString(m) }
+func (*VoteRequest) ProtoMessage()    {}
+func (*VoteRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{22}
+}
+func (m *VoteRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VoteRequest.Unmarshal(m, b)
+}
+func (m *VoteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VoteRequest.Marshal(b, m, deterministic)
+}
+func (dst *VoteRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VoteRequest.Merge(dst, src)
+}
+func (m *VoteRequest) XXX_Size() int {
+	return xxx_messageInfo_VoteRequest.Size(m)
+}
+func (m *VoteRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_VoteRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VoteRequest proto.InternalMessageInfo
+
+func (m *VoteRequest) GetPostUid() string {
+	if m != nil {
+		return m.PostUid
+	}
+	return ""
+}
+
+func (m *VoteRequest) GetUserUid() string {
+	if m != nil {
+		return m.UserUid
+	}
+	return ""
+}
+
+func (m *VoteRequest) GetDirection() VoteDirection {
+	if m != nil {
+		return m.Direction
+	}
+	return VoteDirection_NONE
+}
+
+type VoteResponse struct {
+	Upvotes              int32    `protobuf:"varint,1,opt,name=upvotes,proto3" json:"upvotes,omitempty"`
+	Downvotes            int32    `protobuf:"varint,2,opt,name=downvotes,proto3" json:"downvotes,omitempty"`
+	Score                int32    `protobuf:"varint,3,opt,name=score,proto3" json:"score,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VoteResponse) Reset()         { *m = VoteResponse{} }
+func (m *VoteResponse) String() string { return proto.CompactTextString(m) }
+func (*VoteResponse) ProtoMessage()    {}
+func (*VoteResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{23}
+}
+func (m *VoteResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VoteResponse.Unmarshal(m, b)
+}
+func (m *VoteResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VoteResponse.Marshal(b, m, deterministic)
+}
+func (dst *VoteResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VoteResponse.Merge(dst, src)
+}
+func (m *VoteResponse) XXX_Size() int {
+	return xxx_messageInfo_VoteResponse.Size(m)
+}
+func (m *VoteResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_VoteResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VoteResponse proto.InternalMessageInfo
+
+func (m *VoteResponse) GetUpvotes() int32 {
+	if m != nil {
+		return m.Upvotes
+	}
+	return 0
+}
+
+func (m *VoteResponse) GetDownvotes() int32 {
+	if m != nil {
+		return m.Downvotes
+	}
+	return 0
+}
+
+func (m *VoteResponse) GetScore() int32 {
+	if m != nil {
+		return m.Score
+	}
+	return 0
+}
+
+type RemoveVoteRequest struct {
+	PostUid              string   `protobuf:"bytes,1,opt,name=postUid,proto3" json:"postUid,omitempty"`
+	UserUid              string   `protobuf:"bytes,2,opt,name=userUid,proto3" json:"userUid,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RemoveVoteRequest) Reset()         { *m = RemoveVoteRequest{} }
+func (m *RemoveVoteRequest) String() string { return proto.CompactTextString(m) }
+func (*RemoveVoteRequest) ProtoMessage()    {}
+func (*RemoveVoteRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{24}
+}
+func (m *RemoveVoteRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RemoveVoteRequest.Unmarshal(m, b)
+}
+func (m *RemoveVoteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RemoveVoteRequest.Marshal(b, m, deterministic)
+}
+func (dst *RemoveVoteRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RemoveVoteRequest.Merge(dst, src)
+}
+func (m *RemoveVoteRequest) XXX_Size() int {
+	return xxx_messageInfo_RemoveVoteRequest.Size(m)
+}
+func (m *RemoveVoteRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RemoveVoteRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RemoveVoteRequest proto.InternalMessageInfo
+
+func (m *RemoveVoteRequest) GetPostUid() string {
+	if m != nil {
+		return m.PostUid
+	}
+	return ""
+}
+
+func (m *RemoveVoteRequest) GetUserUid() string {
+	if m != nil {
+		return m.UserUid
+	}
+	return ""
+}
+
+type RemoveVoteResponse struct {
+	Upvotes              int32    `protobuf:"varint,1,opt,name=upvotes,proto3" json:"upvotes,omitempty"`
+	Downvotes            int32    `protobuf:"varint,2,opt,name=downvotes,proto3" json:"downvotes,omitempty"`
+	Score                int32    `protobuf:"varint,3,opt,name=score,proto3" json:"score,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RemoveVoteResponse) Reset()         { *m = RemoveVoteResponse{} }
+func (m *RemoveVoteResponse) String() string { return proto.CompactTextString(m) }
+func (*RemoveVoteResponse) ProtoMessage()    {}
+func (*RemoveVoteResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{25}
+}
+func (m *RemoveVoteResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RemoveVoteResponse.Unmarshal(m, b)
+}
+func (m *RemoveVoteResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RemoveVoteResponse.Marshal(b, m, deterministic)
+}
+func (dst *RemoveVoteResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RemoveVoteResponse.Merge(dst, src)
+}
+func (m *RemoveVoteResponse) XXX_Size() int {
+	return xxx_messageInfo_RemoveVoteResponse.Size(m)
+}
+func (m *RemoveVoteResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RemoveVoteResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RemoveVoteResponse proto.InternalMessageInfo
+
+func (m *RemoveVoteResponse) GetUpvotes() int32 {
+	if m != nil {
+		return m.Upvotes
+	}
+	return 0
+}
+
+func (m *RemoveVoteResponse) GetDownvotes() int32 {
+	if m != nil {
+		return m.Downvotes
+	}
+	return 0
+}
+
+func (m *RemoveVoteResponse) GetScore() int32 {
+	if m != nil {
+		return m.Score
+	}
+	return 0
+}
+
+type SearchPostsRequest struct {
+	Query                string   `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	CategoryUid          string   `protobuf:"bytes,2,opt,name=categoryUid,proto3" json:"categoryUid,omitempty"`
+	UserUid              string   `protobuf:"bytes,3,opt,name=userUid,proto3" json:"userUid,omitempty"`
+	PageSize             int32    `protobuf:"varint,4,opt,name=pageSize,proto3" json:"pageSize,omitempty"`
+	PageNumber           int32    `protobuf:"varint,5,opt,name=pageNumber,proto3" json:"pageNumber,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SearchPostsRequest) Reset()         { *m = SearchPostsRequest{} }
+func (m *SearchPostsRequest) String() string { return proto.CompactTextString(m) }
+func (*SearchPostsRequest) ProtoMessage()    {}
+func (*SearchPostsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{26}
+}
+func (m *SearchPostsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SearchPostsRequest.Unmarshal(m, b)
+}
+func (m *SearchPostsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SearchPostsRequest.Marshal(b, m, deterministic)
+}
+func (dst *SearchPostsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SearchPostsRequest.Merge(dst, src)
+}
+func (m *SearchPostsRequest) XXX_Size() int {
+	return xxx_messageInfo_SearchPostsRequest.Size(m)
+}
+func (m *SearchPostsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SearchPostsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SearchPostsRequest proto.InternalMessageInfo
+
+func (m *SearchPostsRequest) GetQuery() string {
+	if m != nil {
+		return m.Query
+	}
+	return ""
+}
+
+func (m *SearchPostsRequest) GetCategoryUid() string {
+	if m != nil {
+		return m.CategoryUid
+	}
+	return ""
+}
+
+func (m *SearchPostsRequest) GetUserUid() string {
+	if m != nil {
+		return m.UserUid
+	}
+	return ""
+}
+
+func (m *SearchPostsRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *SearchPostsRequest) GetPageNumber() int32 {
+	if m != nil {
+		return m.PageNumber
+	}
+	return 0
+}
+
+type SearchResult struct {
+	Post                 *SinglePost `protobuf:"bytes,1,opt,name=post,proto3" json:"post,omitempty"`
+	Relevance            float32     `protobuf:"fixed32,2,opt,name=relevance,proto3" json:"relevance,omitempty"`
+	TitleHighlight       string      `protobuf:"bytes,3,opt,name=titleHighlight,proto3" json:"titleHighlight,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *SearchResult) Reset()         { *m = SearchResult{} }
+func (m *SearchResult) String() string { return proto.CompactTextString(m) }
+func (*SearchResult) ProtoMessage()    {}
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{27}
+}
+func (m *SearchResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SearchResult.Unmarshal(m, b)
+}
+func (m *SearchResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SearchResult.Marshal(b, m, deterministic)
+}
+func (dst *SearchResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SearchResult.Merge(dst, src)
+}
+func (m *SearchResult) XXX_Size() int {
+	return xxx_messageInfo_SearchResult.Size(m)
+}
+func (m *SearchResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_SearchResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SearchResult proto.InternalMessageInfo
+
+func (m *SearchResult) GetPost() *SinglePost {
+	if m != nil {
+		return m.Post
+	}
+	return nil
+}
+
+func (m *SearchResult) GetRelevance() float32 {
+	if m != nil {
+		return m.Relevance
+	}
+	return 0
+}
+
+func (m *SearchResult) GetTitleHighlight() string {
+	if m != nil {
+		return m.TitleHighlight
+	}
 	return ""
 }
 
+type SearchPostsResponse struct {
+	Results              []*SearchResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	PageSize             int32           `protobuf:"varint,2,opt,name=pageSize,proto3" json:"pageSize,omitempty"`
+	PageNumber           int32           `protobuf:"varint,3,opt,name=pageNumber,proto3" json:"pageNumber,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *SearchPostsResponse) Reset()         { *m = SearchPostsResponse{} }
+func (m *SearchPostsResponse) String() string { return proto.CompactTextString(m) }
+func (*SearchPostsResponse) ProtoMessage()    {}
+func (*SearchPostsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_post_125dfb2770aac849, []int{28}
+}
+func (m *SearchPostsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SearchPostsResponse.Unmarshal(m, b)
+}
+func (m *SearchPostsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SearchPostsResponse.Marshal(b, m, deterministic)
+}
+func (dst *SearchPostsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SearchPostsResponse.Merge(dst, src)
+}
+func (m *SearchPostsResponse) XXX_Size() int {
+	return xxx_messageInfo_SearchPostsResponse.Size(m)
+}
+func (m *SearchPostsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SearchPostsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SearchPostsResponse proto.InternalMessageInfo
+
+func (m *SearchPostsResponse) GetResults() []*SearchResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+func (m *SearchPostsResponse) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *SearchPostsResponse) GetPageNumber() int32 {
+	if m != nil {
+		return m.PageNumber
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*ListPostsRequest)(nil), "post.ListPostsRequest")
 	proto.RegisterType((*ListPostsByCategoryRequest)(nil), "post.ListPostsByCategoryRequest")
@@ -679,10 +1757,29 @@ func init() {
 	proto.RegisterType((*UpdatePostResponse)(nil), "post.UpdatePostResponse")
 	proto.RegisterType((*DeletePostRequest)(nil), "post.DeletePostRequest")
 	proto.RegisterType((*DeletePostResponse)(nil), "post.DeletePostResponse")
+	proto.RegisterType((*RestorePostRequest)(nil), "post.RestorePostRequest")
+	proto.RegisterType((*RestorePostResponse)(nil), "post.RestorePostResponse")
+	proto.RegisterType((*PurgePostRequest)(nil), "post.PurgePostRequest")
+	proto.RegisterType((*PurgePostResponse)(nil), "post.PurgePostResponse")
+	proto.RegisterType((*PostRevision)(nil), "post.PostRevision")
+	proto.RegisterType((*ListPostRevisionsRequest)(nil), "post.ListPostRevisionsRequest")
+	proto.RegisterType((*ListPostRevisionsResponse)(nil), "post.ListPostRevisionsResponse")
+	proto.RegisterType((*GetPostRevisionRequest)(nil), "post.GetPostRevisionRequest")
 	proto.RegisterType((*CheckPostExistsRequest)(nil), "post.CheckPostExistsRequest")
 	proto.RegisterType((*CheckPostExistsResponse)(nil), "post.CheckPostExistsResponse")
 	proto.RegisterType((*GetPostOwnerRequest)(nil), "post.GetPostOwnerRequest")
 	proto.RegisterType((*GetPostOwnerResponse)(nil), "post.GetPostOwnerResponse")
+	proto.RegisterType((*VoteRequest)(nil), "post.VoteRequest")
+	proto.RegisterType((*VoteResponse)(nil), "post.VoteResponse")
+	proto.RegisterType((*RemoveVoteRequest)(nil), "post.RemoveVoteRequest")
+	proto.RegisterType((*RemoveVoteResponse)(nil), "post.RemoveVoteResponse")
+	proto.RegisterType((*SearchPostsRequest)(nil), "post.SearchPostsRequest")
+	proto.RegisterType((*SearchResult)(nil), "post.SearchResult")
+	proto.RegisterType((*SearchPostsResponse)(nil), "post.SearchPostsResponse")
+	proto.RegisterEnum("post.SortOrder", SortOrder_name, SortOrder_value)
+	proto.RegisterEnum("post.TimeWindow", TimeWindow_name, TimeWindow_value)
+	proto.RegisterEnum("post.PostKind", PostKind_name, PostKind_value)
+	proto.RegisterEnum("post.VoteDirection", VoteDirection_name, VoteDirection_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -705,6 +1802,13 @@ type PostClient interface {
 	DeletePost(ctx context.Context, in *DeletePostRequest, opts ...grpc.CallOption) (*DeletePostResponse, error)
 	CheckPostExists(ctx context.Context, in *CheckPostExistsRequest, opts ...grpc.CallOption) (*CheckPostExistsResponse, error)
 	GetPostOwner(ctx context.Context, in *GetPostOwnerRequest, opts ...grpc.CallOption) (*GetPostOwnerResponse, error)
+	Vote(ctx context.Context, in *VoteRequest, opts ...grpc.CallOption) (*VoteResponse, error)
+	RemoveVote(ctx context.Context, in *RemoveVoteRequest, opts ...grpc.CallOption) (*RemoveVoteResponse, error)
+	SearchPosts(ctx context.Context, in *SearchPostsRequest, opts ...grpc.CallOption) (*SearchPostsResponse, error)
+	RestorePost(ctx context.Context, in *RestorePostRequest, opts ...grpc.CallOption) (*RestorePostResponse, error)
+	PurgePost(ctx context.Context, in *PurgePostRequest, opts ...grpc.CallOption) (*PurgePostResponse, error)
+	ListPostRevisions(ctx context.Context, in *ListPostRevisionsRequest, opts ...grpc.CallOption) (*ListPostRevisionsResponse, error)
+	GetPostRevision(ctx context.Context, in *GetPostRevisionRequest, opts ...grpc.CallOption) (*PostRevision, error)
 }
 
 type postClient struct {
@@ -787,6 +1891,69 @@ func (c *postClient) GetPostOwner(ctx context.Context, in *GetPostOwnerRequest,
 	return out, nil
 }
 
+func (c *postClient) Vote(ctx context.Context, in *VoteRequest, opts ...grpc.CallOption) (*VoteResponse, error) {
+	out := new(VoteResponse)
+	err := c.cc.Invoke(ctx, "/post.Post/Vote", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postClient) RemoveVote(ctx context.Context, in *RemoveVoteRequest, opts ...grpc.CallOption) (*RemoveVoteResponse, error) {
+	out := new(RemoveVoteResponse)
+	err := c.cc.Invoke(ctx, "/post.Post/RemoveVote", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postClient) SearchPosts(ctx context.Context, in *SearchPostsRequest, opts ...grpc.CallOption) (*SearchPostsResponse, error) {
+	out := new(SearchPostsResponse)
+	err := c.cc.Invoke(ctx, "/post.Post/SearchPosts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postClient) RestorePost(ctx context.Context, in *RestorePostRequest, opts ...grpc.CallOption) (*RestorePostResponse, error) {
+	out := new(RestorePostResponse)
+	err := c.cc.Invoke(ctx, "/post.Post/RestorePost", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postClient) PurgePost(ctx context.Context, in *PurgePostRequest, opts ...grpc.CallOption) (*PurgePostResponse, error) {
+	out := new(PurgePostResponse)
+	err := c.cc.Invoke(ctx, "/post.Post/PurgePost", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postClient) ListPostRevisions(ctx context.Context, in *ListPostRevisionsRequest, opts ...grpc.CallOption) (*ListPostRevisionsResponse, error) {
+	out := new(ListPostRevisionsResponse)
+	err := c.cc.Invoke(ctx, "/post.Post/ListPostRevisions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postClient) GetPostRevision(ctx context.Context, in *GetPostRevisionRequest, opts ...grpc.CallOption) (*PostRevision, error) {
+	out := new(PostRevision)
+	err := c.cc.Invoke(ctx, "/post.Post/GetPostRevision", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PostServer is the server API for Post service.
 type PostServer interface {
 	ListPosts(context.Context, *ListPostsRequest) (*ListPostsResponse, error)
@@ -797,6 +1964,13 @@ type PostServer interface {
 	DeletePost(context.Context, *DeletePostRequest) (*DeletePostResponse, error)
 	CheckPostExists(context.Context, *CheckPostExistsRequest) (*CheckPostExistsResponse, error)
 	GetPostOwner(context.Context, *GetPostOwnerRequest) (*GetPostOwnerResponse, error)
+	Vote(context.Context, *VoteRequest) (*VoteResponse, error)
+	RemoveVote(context.Context, *RemoveVoteRequest) (*RemoveVoteResponse, error)
+	SearchPosts(context.Context, *SearchPostsRequest) (*SearchPostsResponse, error)
+	RestorePost(context.Context, *RestorePostRequest) (*RestorePostResponse, error)
+	PurgePost(context.Context, *PurgePostRequest) (*PurgePostResponse, error)
+	ListPostRevisions(context.Context, *ListPostRevisionsRequest) (*ListPostRevisionsResponse, error)
+	GetPostRevision(context.Context, *GetPostRevisionRequest) (*PostRevision, error)
 }
 
 func RegisterPostServer(s *grpc.Server, srv PostServer) {
@@ -947,6 +2121,132 @@ func _Post_GetPostOwner_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Post_Vote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServer).Vote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/post.Post/Vote",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServer).Vote(ctx, req.(*VoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Post_RemoveVote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveVoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServer).RemoveVote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/post.Post/RemoveVote",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServer).RemoveVote(ctx, req.(*RemoveVoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Post_SearchPosts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchPostsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServer).SearchPosts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/post.Post/SearchPosts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServer).SearchPosts(ctx, req.(*SearchPostsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Post_RestorePost_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestorePostRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServer).RestorePost(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/post.Post/RestorePost",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServer).RestorePost(ctx, req.(*RestorePostRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Post_PurgePost_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgePostRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServer).PurgePost(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/post.Post/PurgePost",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServer).PurgePost(ctx, req.(*PurgePostRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Post_ListPostRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPostRevisionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServer).ListPostRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/post.Post/ListPostRevisions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServer).ListPostRevisions(ctx, req.(*ListPostRevisionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Post_GetPostRevision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPostRevisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServer).GetPostRevision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/post.Post/GetPostRevision",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServer).GetPostRevision(ctx, req.(*GetPostRevisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Post_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "post.Post",
 	HandlerType: (*PostServer)(nil),
@@ -983,50 +2283,134 @@ var _Post_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetPostOwner",
 			Handler:    _Post_GetPostOwner_Handler,
 		},
+		{
+			MethodName: "Vote",
+			Handler:    _Post_Vote_Handler,
+		},
+		{
+			MethodName: "RemoveVote",
+			Handler:    _Post_RemoveVote_Handler,
+		},
+		{
+			MethodName: "SearchPosts",
+			Handler:    _Post_SearchPosts_Handler,
+		},
+		{
+			MethodName: "RestorePost",
+			Handler:    _Post_RestorePost_Handler,
+		},
+		{
+			MethodName: "PurgePost",
+			Handler:    _Post_PurgePost_Handler,
+		},
+		{
+			MethodName: "ListPostRevisions",
+			Handler:    _Post_ListPostRevisions_Handler,
+		},
+		{
+			MethodName: "GetPostRevision",
+			Handler:    _Post_GetPostRevision_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/post/proto/post.proto",
 }
 
-func init() { proto.RegisterFile("pkg/post/proto/post.proto", fileDescriptor_post_eedb255c562f0a82) }
-
-var fileDescriptor_post_eedb255c562f0a82 = []byte{
-	// 583 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x54, 0x5d, 0x6f, 0xd3, 0x30,
-	0x14, 0x55, 0x9a, 0x7e, 0xde, 0x22, 0x68, 0xbd, 0xaa, 0xcb, 0x2c, 0x3e, 0xaa, 0x48, 0x40, 0xc5,
-	0x43, 0xab, 0x95, 0x07, 0x10, 0x42, 0x9a, 0xa0, 0x4c, 0xbc, 0x40, 0x99, 0x32, 0xf6, 0x03, 0xfa,
-	0x71, 0x17, 0xa2, 0xa5, 0x4d, 0x48, 0x5c, 0x8d, 0xed, 0xaf, 0xf0, 0x47, 0x79, 0x44, 0x76, 0x9c,
-	0xc4, 0x8d, 0xd3, 0xed, 0x61, 0x6f, 0xf6, 0xbd, 0xc7, 0xe7, 0xdc, 0x9c, 0x1c, 0x1b, 0x8e, 0xc2,
-	0x2b, 0x77, 0x1c, 0x06, 0x31, 0x1b, 0x87, 0x51, 0xc0, 0x02, 0xb1, 0x1c, 0x89, 0x25, 0xa9, 0xf2,
-	0x35, 0x7d, 0xe1, 0x06, 0x81, 0xeb, 0x63, 0xd2, 0x5e, 0x6c, 0x2f, 0xc7, 0xcc, 0x5b, 0x63, 0xcc,
-	0xe6, 0xeb, 0x30, 0x81, 0xd9, 0x33, 0xe8, 0x7c, 0xf3, 0x62, 0x76, 0x16, 0xc4, 0x2c, 0x76, 0xf0,
-	0xf7, 0x16, 0x63, 0x46, 0x28, 0x34, 0xc3, 0xb9, 0x8b, 0xe7, 0xde, 0x2d, 0x5a, 0xc6, 0xc0, 0x18,
-	0xd6, 0x9c, 0x6c, 0x4f, 0x9e, 0x03, 0xf0, 0xf5, 0x6c, 0xbb, 0x5e, 0x60, 0x64, 0x55, 0x44, 0x57,
-	0xa9, 0xd8, 0xb7, 0x40, 0x33, 0xbe, 0xcf, 0x37, 0xd3, 0x39, 0x43, 0x37, 0x88, 0x6e, 0x52, 0xe6,
-	0x01, 0xb4, 0x97, 0xb2, 0x74, 0xe1, 0xad, 0x04, 0x79, 0xcb, 0x51, 0x4b, 0x3b, 0xda, 0x95, 0x3b,
-	0xb5, 0x4d, 0x4d, 0xfb, 0x1a, 0xba, 0xca, 0xb7, 0xc4, 0x61, 0xb0, 0x89, 0x91, 0xbc, 0x82, 0x1a,
-	0x77, 0x22, 0xb6, 0x8c, 0x81, 0x39, 0x6c, 0x4f, 0x3a, 0x23, 0xe1, 0xd1, 0xb9, 0xb7, 0x71, 0x7d,
-	0xe4, 0x48, 0x27, 0x69, 0x3f, 0x48, 0xd8, 0x86, 0xc7, 0x5f, 0x51, 0xe8, 0xa6, 0x1f, 0xda, 0x01,
-	0x73, 0x9b, 0x7d, 0x20, 0x5f, 0xda, 0xff, 0x0c, 0x80, 0x5c, 0x55, 0x07, 0x10, 0x0b, 0x1a, 0xdb,
-	0x18, 0x23, 0xee, 0x4b, 0x45, 0x54, 0xd3, 0x6d, 0xd1, 0x35, 0x53, 0x77, 0xad, 0x07, 0x35, 0xe6,
-	0x31, 0x1f, 0xad, 0xaa, 0xe8, 0x25, 0x1b, 0xa1, 0x11, 0xf9, 0x56, 0x4d, 0x6a, 0x44, 0x3e, 0x79,
-	0x0f, 0xad, 0x65, 0x84, 0x73, 0x86, 0xab, 0x4f, 0xcc, 0xaa, 0x0f, 0x8c, 0x61, 0x7b, 0x42, 0x47,
-	0x49, 0x44, 0x46, 0x69, 0x44, 0x46, 0x3f, 0xd3, 0x88, 0x38, 0x39, 0x98, 0x7c, 0x00, 0x58, 0x07,
-	0x2b, 0xef, 0xd2, 0x13, 0x47, 0x1b, 0xf7, 0x1e, 0x55, 0xd0, 0xfc, 0xbf, 0x4c, 0x05, 0x91, 0xea,
-	0x50, 0x36, 0xb2, 0x51, 0x32, 0x72, 0x25, 0x1f, 0x59, 0xb1, 0xc5, 0xbc, 0xd3, 0x96, 0xaa, 0x66,
-	0x8b, 0xfd, 0x1d, 0xba, 0x17, 0xe1, 0xaa, 0x20, 0xac, 0x3b, 0x9f, 0x8d, 0x52, 0x29, 0x19, 0xc5,
-	0xcc, 0x46, 0xb1, 0x7b, 0x40, 0x54, 0xba, 0x24, 0x60, 0xf6, 0x4b, 0xe8, 0x7e, 0x41, 0x1f, 0xef,
-	0x11, 0xe1, 0x87, 0x55, 0x98, 0x3c, 0xfc, 0x06, 0xfa, 0xd3, 0x5f, 0xb8, 0xbc, 0xe2, 0xc5, 0xd3,
-	0x3f, 0x9e, 0x72, 0x09, 0x75, 0x86, 0x63, 0x38, 0xd4, 0xb0, 0x32, 0xe4, 0x7d, 0xa8, 0xa3, 0xa8,
-	0x08, 0x7c, 0xd3, 0x91, 0x3b, 0xfb, 0x35, 0x1c, 0xc8, 0x60, 0xfe, 0xb8, 0xde, 0x60, 0xb4, 0x9f,
-	0x7b, 0x02, 0xbd, 0x5d, 0xa0, 0x24, 0xa6, 0xd0, 0x0c, 0x78, 0x21, 0xbf, 0xad, 0xd9, 0x7e, 0xf2,
-	0xb7, 0x0a, 0x55, 0x91, 0xe5, 0x8f, 0xd0, 0xca, 0xee, 0x1d, 0xe9, 0x27, 0x17, 0xac, 0xf8, 0xa8,
-	0xd0, 0x43, 0xad, 0x2e, 0x25, 0xce, 0xe0, 0xa0, 0xe4, 0xc5, 0x20, 0x83, 0x02, 0x5e, 0x7b, 0x4c,
-	0xf6, 0x33, 0x1e, 0x43, 0x43, 0x7e, 0x0c, 0xe9, 0x25, 0x98, 0xdd, 0xdb, 0x49, 0xb5, 0x47, 0x80,
-	0xbc, 0x03, 0xc8, 0x23, 0x4a, 0x24, 0xb3, 0x16, 0xda, 0x92, 0x83, 0x27, 0x00, 0x79, 0x26, 0xd2,
-	0x83, 0x5a, 0xe8, 0xa8, 0xa5, 0x37, 0xe4, 0xb0, 0x27, 0x00, 0x79, 0x2e, 0x52, 0x02, 0x2d, 0x50,
-	0x29, 0x81, 0x1e, 0x21, 0x32, 0x83, 0x27, 0x85, 0x58, 0x90, 0xa7, 0x72, 0xfe, 0xd2, 0x64, 0xd1,
-	0x67, 0x7b, 0xba, 0x92, 0xef, 0x14, 0x1e, 0xa9, 0x51, 0x20, 0x47, 0x3b, 0x16, 0xaa, 0x39, 0xa2,
-	0xb4, 0xac, 0x95, 0xd0, 0x2c, 0xea, 0xe2, 0x51, 0x78, 0xfb, 0x3f, 0x00, 0x00, 0xff, 0xff, 0xc9,
-	0x77, 0x25, 0x7c, 0xa3, 0x06, 0x00, 0x00,
+func init() { proto.RegisterFile("pkg/post/proto/post.proto", fileDescriptor_post_125dfb2770aac849) }
+
+var fileDescriptor_post_125dfb2770aac849 = []byte{
+	// 1473 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x57, 0xd9, 0x6e, 0xdb, 0x46,
+	0x17, 0x0e, 0x17, 0x49, 0xe6, 0xf1, 0x46, 0x8d, 0xfd, 0x3b, 0x34, 0x91, 0x3f, 0x11, 0xd8, 0x20,
+	0x15, 0xdc, 0xd6, 0x69, 0x9c, 0x8b, 0x2e, 0x08, 0x10, 0x38, 0xb6, 0xb2, 0xd4, 0x8e, 0x64, 0xd0,
+	0x72, 0xdc, 0x02, 0x05, 0x0a, 0x59, 0x9c, 0xc8, 0x84, 0x24, 0x52, 0x21, 0x47, 0x76, 0x9c, 0x9b,
+	0x5e, 0x14, 0x28, 0xd0, 0x87, 0xe8, 0x4d, 0x1f, 0xa3, 0x2f, 0xd0, 0x47, 0xe8, 0x4b, 0xf4, 0x21,
+	0x8a, 0x19, 0xce, 0x70, 0x97, 0x92, 0xd6, 0xe8, 0xdd, 0xcc, 0x39, 0x67, 0x0e, 0xcf, 0xf2, 0x9d,
+	0x85, 0xb0, 0x39, 0x19, 0x0e, 0xee, 0x4f, 0xfc, 0x90, 0xdc, 0x9f, 0x04, 0x3e, 0xf1, 0xd9, 0x71,
+	0x9b, 0x1d, 0x91, 0x4a, 0xcf, 0xe6, 0x9d, 0x81, 0xef, 0x0f, 0x46, 0x38, 0x62, 0x9f, 0x4d, 0x5f,
+	0xdf, 0x27, 0xee, 0x18, 0x87, 0xa4, 0x37, 0x9e, 0x44, 0x62, 0x66, 0x23, 0x2f, 0xf0, 0xda, 0xc5,
+	0x23, 0xe7, 0x87, 0x71, 0x2f, 0x1c, 0x46, 0x12, 0xd6, 0x9f, 0x12, 0xe8, 0x87, 0x6e, 0x48, 0x8e,
+	0xfc, 0x90, 0x84, 0x36, 0x7e, 0x33, 0xc5, 0x21, 0x41, 0x26, 0x2c, 0x4c, 0x7a, 0x03, 0x7c, 0xec,
+	0xbe, 0xc3, 0x86, 0xd4, 0x90, 0x9a, 0x15, 0x3b, 0xbe, 0xa3, 0xdb, 0x00, 0xf4, 0xdc, 0x9e, 0x8e,
+	0xcf, 0x70, 0x60, 0xc8, 0x8c, 0x9b, 0xa2, 0xa0, 0x5b, 0xa0, 0xd1, 0x5b, 0xd7, 0x1f, 0x62, 0xcf,
+	0x50, 0x1a, 0x52, 0x53, 0xb3, 0x13, 0x02, 0xfa, 0x08, 0xd4, 0xd0, 0x0f, 0x88, 0xa1, 0x36, 0xa4,
+	0xe6, 0xca, 0xce, 0xea, 0x36, 0x73, 0xe9, 0xd8, 0x0f, 0x48, 0x27, 0x70, 0x70, 0x60, 0x33, 0x26,
+	0x6a, 0x42, 0xf5, 0xd2, 0xf5, 0x1c, 0xff, 0xd2, 0xa8, 0x30, 0x31, 0x3d, 0x12, 0xeb, 0xba, 0x63,
+	0x7c, 0xca, 0xe8, 0x36, 0xe7, 0x53, 0x43, 0xfd, 0xb1, 0x4b, 0x9e, 0xf8, 0xce, 0x95, 0x51, 0x6d,
+	0x48, 0xcd, 0x05, 0x3b, 0xbe, 0x5b, 0x3f, 0xc9, 0x60, 0xc6, 0x9e, 0x3d, 0xb9, 0xda, 0xeb, 0x11,
+	0x3c, 0xf0, 0x83, 0x2b, 0xe1, 0x63, 0x03, 0x16, 0xfb, 0x9c, 0x74, 0xe2, 0x3a, 0xcc, 0x4d, 0xcd,
+	0x4e, 0x93, 0x32, 0x51, 0x90, 0xe7, 0x46, 0x41, 0x99, 0x1f, 0x05, 0x75, 0x56, 0x14, 0x2a, 0x1f,
+	0x16, 0x85, 0xea, 0x3f, 0x88, 0x42, 0x2d, 0x17, 0x85, 0x5f, 0x25, 0xa8, 0xa7, 0xf2, 0x1b, 0x4e,
+	0x7c, 0x2f, 0xc4, 0xe8, 0x1e, 0x54, 0xa8, 0xb2, 0xd0, 0x90, 0x1a, 0x4a, 0x73, 0x51, 0xa8, 0x3e,
+	0x76, 0xbd, 0xc1, 0x08, 0x53, 0x49, 0x3b, 0x62, 0x5f, 0x2b, 0x04, 0x77, 0x61, 0xd9, 0xc3, 0x6f,
+	0xc9, 0x51, 0x2e, 0x0c, 0x59, 0xa2, 0xf5, 0x0d, 0xac, 0x3c, 0xc3, 0xcc, 0x3a, 0x91, 0x18, 0x1d,
+	0x94, 0x69, 0x9c, 0x10, 0x7a, 0x44, 0xf7, 0x60, 0xc5, 0xf5, 0xfa, 0xa3, 0xa9, 0x83, 0xf7, 0xf1,
+	0x08, 0x13, 0xec, 0x30, 0x5b, 0x16, 0xec, 0x1c, 0xd5, 0xfa, 0x59, 0x05, 0x48, 0x7c, 0x28, 0x51,
+	0x64, 0x40, 0x6d, 0x1a, 0xe2, 0xe0, 0xc4, 0x8d, 0x34, 0x68, 0xb6, 0xb8, 0xe6, 0xd1, 0xa0, 0x14,
+	0xd1, 0xb0, 0x0e, 0x15, 0xe2, 0x92, 0x11, 0xe6, 0x6e, 0x44, 0x17, 0xf6, 0x8d, 0x60, 0x64, 0x54,
+	0xf8, 0x37, 0x82, 0x11, 0xfa, 0x12, 0xb4, 0x7e, 0x80, 0x7b, 0x04, 0x3b, 0xbb, 0x84, 0x65, 0x6e,
+	0x71, 0xc7, 0xdc, 0x8e, 0xca, 0x70, 0x5b, 0x94, 0xe1, 0x76, 0x57, 0xd4, 0xa9, 0x9d, 0x08, 0xa3,
+	0xaf, 0x01, 0xc6, 0xbe, 0xe3, 0xbe, 0x76, 0xd9, 0xd3, 0xda, 0x7b, 0x9f, 0xa6, 0xa4, 0x99, 0x67,
+	0x93, 0x0b, 0x9f, 0xe0, 0xd0, 0x58, 0x60, 0x99, 0x10, 0x57, 0x8a, 0x44, 0xc7, 0xbf, 0xf4, 0x22,
+	0x9e, 0xc6, 0x78, 0x09, 0x81, 0x7a, 0x15, 0xf6, 0xfd, 0x00, 0x1b, 0xc0, 0x38, 0xd1, 0x05, 0x59,
+	0xa0, 0x0e, 0x5d, 0xcf, 0x31, 0x16, 0x19, 0xf0, 0x56, 0x22, 0x74, 0xd0, 0x98, 0x1e, 0xb8, 0x9e,
+	0x63, 0x33, 0x1e, 0x42, 0xa0, 0x9e, 0x51, 0xc0, 0x2d, 0x31, 0xd7, 0xd9, 0x99, 0xfa, 0xee, 0x44,
+	0xb9, 0xd8, 0x25, 0xc6, 0xf2, 0xfb, 0x7d, 0x8f, 0x85, 0x29, 0xd0, 0x02, 0x7c, 0xe1, 0x86, 0xae,
+	0xef, 0x19, 0x2b, 0x11, 0xd0, 0xc4, 0x1d, 0x6d, 0x40, 0x15, 0x3b, 0x2e, 0x4d, 0xfb, 0x2a, 0x4b,
+	0x3b, 0xbf, 0x51, 0x9f, 0x2f, 0x70, 0xc0, 0x9e, 0xe8, 0x0d, 0xa9, 0xa9, 0xd8, 0xe2, 0x4a, 0x9b,
+	0x5a, 0x7d, 0x8f, 0xc5, 0x35, 0x0d, 0xac, 0x38, 0x83, 0x52, 0x49, 0x06, 0xe5, 0x24, 0x83, 0x29,
+	0x94, 0x28, 0x73, 0x51, 0xa2, 0x16, 0x51, 0x22, 0x22, 0x57, 0xf9, 0x80, 0xc8, 0x55, 0x53, 0x91,
+	0xa3, 0x10, 0x77, 0xf0, 0x78, 0xe2, 0x13, 0xec, 0xf5, 0xaf, 0x0e, 0x70, 0x54, 0xc8, 0x9a, 0x9d,
+	0xa3, 0x5a, 0x7f, 0x48, 0x50, 0x3f, 0x99, 0x38, 0x39, 0xcf, 0x8a, 0x48, 0x8f, 0x7d, 0x95, 0x4b,
+	0x7c, 0x55, 0x12, 0x5f, 0x85, 0x2d, 0x6a, 0xca, 0x96, 0x26, 0xac, 0xe2, 0xb7, 0x13, 0xdc, 0x27,
+	0xd8, 0x79, 0xc5, 0xe3, 0x5b, 0x61, 0xf1, 0xcd, 0x93, 0x29, 0x62, 0xa7, 0xcc, 0x98, 0x97, 0xbd,
+	0x70, 0x38, 0x13, 0xec, 0x4f, 0xe9, 0xcc, 0xa1, 0x12, 0x76, 0x4a, 0xda, 0xda, 0x06, 0x94, 0x76,
+	0x84, 0x37, 0xa6, 0x54, 0x4e, 0xa5, 0x6c, 0x4e, 0x3b, 0x50, 0x8f, 0xea, 0x7c, 0xbe, 0xe3, 0x25,
+	0xc6, 0xcb, 0xa5, 0xc6, 0x5b, 0xeb, 0x80, 0xd2, 0x0a, 0x23, 0x03, 0xac, 0x7b, 0x80, 0x6c, 0x1c,
+	0x12, 0x3f, 0x98, 0xff, 0x1d, 0xeb, 0x7f, 0xb0, 0x96, 0x91, 0xe3, 0xcf, 0xef, 0x82, 0x7e, 0x34,
+	0x0d, 0x06, 0xef, 0x79, 0xbc, 0x06, 0xf5, 0x94, 0x14, 0x7f, 0xfa, 0xbb, 0x04, 0x4b, 0x11, 0x81,
+	0xe3, 0xde, 0x80, 0x1a, 0x85, 0x4f, 0x32, 0x9d, 0xc4, 0x35, 0x53, 0x2d, 0x72, 0xae, 0x5a, 0xe2,
+	0xcc, 0x2b, 0x25, 0x99, 0x57, 0x8b, 0x99, 0xaf, 0x64, 0xeb, 0xf7, 0xdf, 0xf5, 0x2e, 0xeb, 0x1c,
+	0x0c, 0x31, 0x65, 0x84, 0xfd, 0xe1, 0xec, 0x24, 0x5d, 0x63, 0xac, 0x58, 0xbf, 0x48, 0xb0, 0x59,
+	0xf2, 0x29, 0x8e, 0x9f, 0xcf, 0x41, 0x13, 0x91, 0x10, 0xc3, 0x0d, 0x25, 0x45, 0x28, 0xe4, 0xed,
+	0x44, 0xe8, 0x5a, 0xb6, 0x3c, 0x85, 0x8d, 0x78, 0x78, 0x71, 0xcd, 0xf3, 0x7c, 0x9e, 0x95, 0x33,
+	0x6b, 0x0b, 0x36, 0xf6, 0xce, 0x71, 0x7f, 0x48, 0x35, 0xb5, 0xde, 0xba, 0xa9, 0x4d, 0xac, 0x88,
+	0x9d, 0x07, 0x70, 0xb3, 0x20, 0xcb, 0x9d, 0xa7, 0x8d, 0x92, 0x51, 0x0c, 0x89, 0x37, 0x4a, 0x76,
+	0xb3, 0x3e, 0x86, 0x35, 0x6e, 0x66, 0xe7, 0xd2, 0xc3, 0xc1, 0x6c, 0xdd, 0x3b, 0xb0, 0x9e, 0x15,
+	0xe4, 0x8a, 0xe9, 0x82, 0x41, 0x09, 0x09, 0x14, 0xe3, 0xbb, 0x45, 0x60, 0xf1, 0x95, 0x4f, 0xb0,
+	0x50, 0x3a, 0x1b, 0xb4, 0xb3, 0x87, 0xef, 0x03, 0xd0, 0x1c, 0x37, 0xc0, 0x7d, 0x42, 0x63, 0xa3,
+	0xb0, 0xce, 0xb9, 0x16, 0x25, 0x8d, 0x6a, 0xde, 0x17, 0x2c, 0x3b, 0x91, 0xb2, 0xbe, 0x87, 0xa5,
+	0xe8, 0xab, 0x49, 0xdf, 0x10, 0xf3, 0x4f, 0x9a, 0x33, 0xff, 0xe4, 0x99, 0xf3, 0x4f, 0x49, 0xcd,
+	0x3f, 0xeb, 0x19, 0xd4, 0x6d, 0x3c, 0xf6, 0x2f, 0xf0, 0x35, 0x3d, 0xb3, 0xce, 0x00, 0xa5, 0x15,
+	0xfd, 0x27, 0xc6, 0xfe, 0x26, 0x01, 0x3a, 0xc6, 0xbd, 0xa0, 0x7f, 0x9e, 0xd9, 0xe1, 0xd7, 0xa1,
+	0xf2, 0x66, 0x8a, 0x83, 0x2b, 0x31, 0xed, 0xd8, 0x25, 0x3f, 0xc1, 0xe4, 0xe2, 0x04, 0x9b, 0x3d,
+	0xfd, 0xd2, 0x95, 0xa2, 0xce, 0xad, 0x94, 0x4a, 0xa1, 0x52, 0xde, 0xc1, 0x52, 0x64, 0xa3, 0x8d,
+	0xc3, 0xe9, 0x88, 0xa0, 0xbb, 0xc0, 0xfe, 0x60, 0x98, 0x71, 0x65, 0xfb, 0x27, 0xe3, 0xd2, 0x70,
+	0x04, 0x78, 0x84, 0x2f, 0x7a, 0x5e, 0x3f, 0x2a, 0x4e, 0xd9, 0x4e, 0x08, 0x74, 0x66, 0xb2, 0xe6,
+	0xf6, 0xdc, 0x1d, 0x9c, 0x8f, 0xdc, 0xc1, 0x39, 0xe1, 0x06, 0xe7, 0xa8, 0xd6, 0x8f, 0xb0, 0x96,
+	0x89, 0x0f, 0xcf, 0xc2, 0xa7, 0x50, 0x0b, 0x98, 0x31, 0xb9, 0x46, 0x91, 0xb6, 0xd3, 0x16, 0x22,
+	0xd7, 0x69, 0x13, 0x5b, 0x5f, 0x81, 0x16, 0x2f, 0xf7, 0xa8, 0x06, 0x4a, 0xbb, 0x75, 0xaa, 0xdf,
+	0xa0, 0x87, 0xe7, 0x9d, 0xae, 0x2e, 0xd1, 0x43, 0xb7, 0x73, 0xa4, 0xcb, 0xa8, 0x0e, 0xcb, 0x7b,
+	0x9d, 0x76, 0xd7, 0xee, 0xbc, 0x6a, 0xd9, 0xc7, 0x2f, 0x76, 0x0f, 0x75, 0x65, 0xeb, 0x21, 0x40,
+	0xb2, 0xf0, 0x53, 0xc9, 0xdd, 0xc3, 0xc3, 0xe8, 0xed, 0xfe, 0xee, 0x77, 0xba, 0x84, 0x16, 0x40,
+	0x3d, 0x6d, 0xb5, 0x0e, 0x74, 0x19, 0x69, 0x50, 0x79, 0xd9, 0x69, 0x77, 0x9f, 0xeb, 0xca, 0xd6,
+	0x6d, 0x58, 0x10, 0x2b, 0x07, 0x15, 0x38, 0x7c, 0xd1, 0x3e, 0xd0, 0x6f, 0xd0, 0x53, 0xb7, 0xf5,
+	0x6d, 0x57, 0x97, 0xb6, 0x3e, 0x81, 0xe5, 0x4c, 0x61, 0x51, 0x56, 0xbb, 0xd3, 0x6e, 0xe9, 0x37,
+	0x50, 0x15, 0xe4, 0x93, 0xa3, 0x48, 0xef, 0x7e, 0xe7, 0xb4, 0xad, 0xcb, 0x3b, 0x7f, 0xd5, 0x40,
+	0xa5, 0xda, 0xd0, 0x23, 0xd0, 0xe2, 0x1f, 0x09, 0xb4, 0x11, 0xc5, 0x2a, 0xff, 0xe7, 0x68, 0xde,
+	0x2c, 0xd0, 0x79, 0xb4, 0x8f, 0x60, 0xad, 0xe4, 0x67, 0x0c, 0x35, 0x72, 0xf2, 0x85, 0xff, 0xb4,
+	0xd9, 0x1a, 0x1f, 0x40, 0x8d, 0x37, 0x2b, 0xb4, 0x1e, 0xc9, 0x64, 0x7f, 0x24, 0xcc, 0x02, 0xaa,
+	0xd0, 0x17, 0x00, 0xc9, 0x5a, 0x88, 0xb8, 0xe6, 0xc2, 0xa2, 0x58, 0xf2, 0xf0, 0x31, 0x40, 0xb2,
+	0xac, 0x88, 0x87, 0x85, 0x3d, 0xcc, 0x34, 0x8a, 0x0c, 0x6e, 0xec, 0x63, 0x80, 0x64, 0xd9, 0x10,
+	0x0a, 0x0a, 0xfb, 0x8c, 0x69, 0x14, 0x19, 0x5c, 0x41, 0x1b, 0x56, 0x73, 0x6d, 0x1f, 0xdd, 0xe2,
+	0xf6, 0x97, 0x4e, 0x0e, 0xf3, 0xff, 0x33, 0xb8, 0x5c, 0x5f, 0x0b, 0x96, 0xd2, 0xad, 0x1e, 0x6d,
+	0x66, 0x42, 0x98, 0x9e, 0x13, 0xa6, 0x59, 0xc6, 0xe2, 0x6a, 0x3e, 0x03, 0x95, 0x42, 0x09, 0xd5,
+	0x93, 0x7e, 0x2d, 0x9e, 0xa1, 0x34, 0x29, 0x09, 0x43, 0xd2, 0x0f, 0x45, 0x18, 0x0a, 0xad, 0xd6,
+	0x34, 0x8a, 0x0c, 0xae, 0xe0, 0x09, 0x2c, 0xa6, 0x6a, 0x19, 0x19, 0xe9, 0x92, 0xcd, 0x00, 0x71,
+	0xb3, 0x84, 0x93, 0xe8, 0x48, 0xad, 0x6e, 0x28, 0xfe, 0x58, 0x7e, 0xeb, 0x33, 0x37, 0x4b, 0x38,
+	0x5c, 0xc7, 0x23, 0xd0, 0xe2, 0x0d, 0x4e, 0x14, 0x43, 0x7e, 0xf1, 0x33, 0x6f, 0x16, 0xe8, 0xfc,
+	0x75, 0x37, 0xf9, 0x27, 0x8f, 0x57, 0x18, 0x74, 0x3b, 0x0b, 0xf4, 0xfc, 0x1a, 0x65, 0xde, 0x99,
+	0xc9, 0xe7, 0x5a, 0xf7, 0x60, 0x35, 0xb7, 0x8d, 0x08, 0x88, 0x94, 0x2f, 0x29, 0x66, 0xc9, 0x66,
+	0x74, 0x56, 0x65, 0x7b, 0xde, 0xc3, 0xbf, 0x07, 0x00, 0x20, 0x2d, 0xe6, 0x50, 0x7c, 0x12, 0x00,
+	0x00,
 }
@@ -0,0 +1,79 @@
+package post
+
+import (
+	"math"
+	"time"
+)
+
+// sortOrder defines order of posts in list
+type sortOrder int
+
+const (
+	sortNew sortOrder = iota
+	sortHot
+	sortTop
+	sortControversial
+)
+
+// hotEpoch is the start of hot rank time scale
+const hotEpoch = 1514764800
+
+// rankColumn returns column posts are ranked by, empty string means newest first
+func (o sortOrder) rankColumn() string {
+	switch o {
+	case sortHot:
+		return "hot_rank"
+	case sortTop:
+		return "score"
+	case sortControversial:
+		return "controversy"
+	default:
+		return ""
+	}
+}
+
+// rank returns value of post rank column for sort order
+func (o sortOrder) rank(p *Post) float64 {
+	switch o {
+	case sortHot:
+		return p.HotRank
+	case sortTop:
+		return float64(p.Score())
+	case sortControversial:
+		return p.Controversy
+	default:
+		return 0
+	}
+}
+
+// hotRank returns score decayed by age: every 12.5 hours newer post needs 10 times fewer votes to rank the same
+func hotRank(score int32, createdAt time.Time) float64 {
+	order := math.Log10(math.Max(math.Abs(float64(score)), 1))
+	var sign float64
+	switch {
+	case score > 0:
+		sign = 1
+	case score < 0:
+		sign = -1
+	}
+
+	seconds := float64(createdAt.Unix() - hotEpoch)
+	return sign*order + seconds/45000
+}
+
+// controversy is high for posts with many votes split evenly
+func controversy(upvotes, downvotes int32) float64 {
+	if upvotes <= 0 || downvotes <= 0 {
+		return 0
+	}
+
+	magnitude := float64(upvotes + downvotes)
+	var balance float64
+	if upvotes > downvotes {
+		balance = float64(downvotes) / float64(upvotes)
+	} else {
+		balance = float64(upvotes) / float64(downvotes)
+	}
+
+	return math.Pow(magnitude, balance)
+}
@@ -3,45 +3,360 @@ package post
 import (
 	"fmt"
 	"net"
+	"net/http"
+	"sync"
+	"time"
 
 	pb "github.com/andreymgn/RSOI-post/pkg/post/proto"
 	"github.com/grpc-ecosystem/grpc-opentracing/go/otgrpc"
 	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
+const (
+	// StoragePostgres keeps posts in Postgres database
+	StoragePostgres = "postgres"
+	// StorageMemory keeps posts in memory until service stops, for development only
+	StorageMemory = "memory"
+	// DefaultCertFile is used when Config.CertFile is not set
+	DefaultCertFile = "/cert.pem"
+	// DefaultKeyFile is used when Config.KeyFile is not set
+	DefaultKeyFile = "/key.pem"
+	// DefaultMaxBodyLength is used when Config.MaxBodyLength is not set
+	DefaultMaxBodyLength = 40000
+	// DefaultPurgeInterval is used when Config.PurgeInterval is not set
+	DefaultPurgeInterval = time.Hour
+	// DefaultIdempotencyKeyTTL is used when Config.IdempotencyKeyTTL is not set
+	DefaultIdempotencyKeyTTL = 24 * time.Hour
+	// DefaultHealthCheckInterval is used when Config.HealthCheckInterval is not set
+	DefaultHealthCheckInterval = 10 * time.Second
+	// DefaultPageSize is used when Config.DefaultPageSize is not set
+	DefaultPageSize = 10
+	// DefaultMaxPageSize is used when Config.MaxPageSize is not set
+	DefaultMaxPageSize = 100
+	// DefaultShutdownTimeout is used when Config.ShutdownTimeout is not set
+	DefaultShutdownTimeout = 15 * time.Second
+	// DefaultQueryTimeout is used when Config.QueryTimeout is not set
+	DefaultQueryTimeout = 5 * time.Second
+)
+
+// Config holds posts service settings
+type Config struct {
+	// MaxBodyLength is maximum number of characters in text post body
+	MaxBodyLength int
+	// PurgeRetention is how long deleted posts are kept before purge, zero keeps them forever
+	PurgeRetention time.Duration
+	// PurgeInterval is how often deleted posts and expired idempotency keys are purged
+	PurgeInterval time.Duration
+	// IdempotencyKeyTTL is how long CreatePost retries with the same key return the same post
+	IdempotencyKeyTTL time.Duration
+	// HTTPPort is port of HTTP/JSON API, zero disables it
+	HTTPPort int
+	// HealthCheckInterval is how often database is pinged to update health status
+	HealthCheckInterval time.Duration
+	// MetricsPort is port of Prometheus /metrics endpoint, zero disables it
+	MetricsPort int
+	// TLSMode defines how gRPC and HTTP/JSON listeners are secured, TLSServer by default
+	TLSMode TLSMode
+	// CertFile and KeyFile are server certificate and its private key
+	CertFile string
+	KeyFile  string
+	// ClientCAFile holds certificates client certificates are verified against in TLSMutual mode
+	ClientCAFile string
+	// DefaultPageSize is used when request doesn't set page size
+	DefaultPageSize int32
+	// MaxPageSize limits requested page size, larger pages are rejected
+	MaxPageSize int32
+	// MaxOpenConns and MaxIdleConns limit database connection pool, zero keeps database/sql defaults
+	MaxOpenConns int
+	MaxIdleConns int
+	// ConnMaxLifetime is how long database connection may be reused, zero means forever
+	ConnMaxLifetime time.Duration
+	// QueryTimeout limits every datastore call unless request deadline is earlier
+	QueryTimeout time.Duration
+	// Storage is StoragePostgres (default) or StorageMemory, connection string is ignored for the latter
+	Storage string
+	// AutoMigrate makes NewServer apply pending schema migrations
+	AutoMigrate bool
+	// ShutdownTimeout is how long Shutdown waits for running requests before cancelling them
+	ShutdownTimeout time.Duration
+	// Logger receives log of every call and background job, logrus standard logger by default
+	Logger logrus.FieldLogger
+}
+
 // Server implements posts service
 type Server struct {
-	db datastore
+	db     datastore
+	conf   Config
+	health *health.Server
+	// done is closed on shutdown to stop background jobs
+	done chan struct{}
+	// healthMu makes closing done and health updates atomic, so no update follows shutdown
+	healthMu sync.Mutex
+
+	mu          sync.Mutex
+	grpcServer  *grpc.Server
+	httpServers []*http.Server
 }
 
 // NewServer returns a new server
-func NewServer(connString string) (*Server, error) {
+func NewServer(connString string, conf Config) (*Server, error) {
+	if conf.MaxBodyLength == 0 {
+		conf.MaxBodyLength = DefaultMaxBodyLength
+	}
+
+	if conf.PurgeInterval == 0 {
+		conf.PurgeInterval = DefaultPurgeInterval
+	}
+
+	if conf.IdempotencyKeyTTL == 0 {
+		conf.IdempotencyKeyTTL = DefaultIdempotencyKeyTTL
+	}
+
+	if conf.HealthCheckInterval == 0 {
+		conf.HealthCheckInterval = DefaultHealthCheckInterval
+	}
+
+	if conf.DefaultPageSize == 0 {
+		conf.DefaultPageSize = DefaultPageSize
+	}
+
+	if conf.MaxPageSize == 0 {
+		conf.MaxPageSize = DefaultMaxPageSize
+	}
+
+	if conf.ShutdownTimeout == 0 {
+		conf.ShutdownTimeout = DefaultShutdownTimeout
+	}
+
+	if conf.QueryTimeout == 0 {
+		conf.QueryTimeout = DefaultQueryTimeout
+	}
+
+	if conf.Logger == nil {
+		conf.Logger = logrus.StandardLogger()
+	}
+
+	if conf.TLSMode == "" {
+		conf.TLSMode = TLSServer
+	}
+
+	if conf.CertFile == "" {
+		conf.CertFile = DefaultCertFile
+	}
+
+	if conf.KeyFile == "" {
+		conf.KeyFile = DefaultKeyFile
+	}
+
+	var store datastore
+	switch conf.Storage {
+	case StoragePostgres, "":
+		db, err := openPostgres(connString, conf)
+		if err != nil {
+			return nil, err
+		}
+
+		store = db
+	case StorageMemory:
+		store = newMemoryDB()
+	default:
+		return nil, fmt.Errorf("unknown storage %q", conf.Storage)
+	}
+
+	return &Server{db: instrumentedDB{store}, conf: conf, health: newHealthServer(), done: make(chan struct{})}, nil
+}
+
+// openPostgres connects to Postgres, migrates it if AutoMigrate is set and exports its connection pool stats
+func openPostgres(connString string, conf Config) (*db, error) {
 	db, err := newDB(connString)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Server{db}, nil
+	db.SetMaxOpenConns(conf.MaxOpenConns)
+	if conf.MaxIdleConns != 0 {
+		db.SetMaxIdleConns(conf.MaxIdleConns)
+	}
+
+	db.SetConnMaxLifetime(conf.ConnMaxLifetime)
+	db.queryTimeout = conf.QueryTimeout
+
+	if conf.AutoMigrate {
+		n, err := migrateUp(db.DB)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+
+		conf.Logger.WithField("count", n).Info("applied migrations")
+	}
+
+	if err := registerDBStats(db.DB); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
 }
 
-// Start starts a server
+// callInterceptor is run around every gRPC and HTTP call inside span of the call
+func (s *Server) callInterceptor() grpc.UnaryServerInterceptor {
+	return chainInterceptors(metricsInterceptor, chainInterceptors(identityInterceptor, s.loggingInterceptor))
+}
+
+// Start starts a server, HTTP/JSON API and metrics are served too if their ports are set.
+// It returns nil after Shutdown
 func (s *Server) Start(port int, tracer opentracing.Tracer) error {
-	creds, err := credentials.NewServerTLSFromFile("/cert.pem", "/key.pem")
+	tlsConfig, err := s.conf.tlsConfig()
 	if err != nil {
 		return err
 	}
 
-	server := grpc.NewServer(
-		grpc.Creds(creds),
-		grpc.UnaryInterceptor(otgrpc.OpenTracingServerInterceptor(tracer)),
-	)
+	tracing := otgrpc.OpenTracingServerInterceptor(tracer)
+	opts := []grpc.ServerOption{grpc.UnaryInterceptor(chainInterceptors(tracing, s.callInterceptor()))}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	server := grpc.NewServer(opts...)
 	pb.RegisterPostServer(server, s)
+	healthpb.RegisterHealthServer(server, s.health)
+
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
 		return err
 	}
 
-	return server.Serve(lis)
+	var httpServers []*http.Server
+	if s.conf.HTTPPort != 0 {
+		httpServers = append(httpServers, &http.Server{
+			Addr:      fmt.Sprintf(":%d", s.conf.HTTPPort),
+			Handler:   newGateway(s, tracer, s.callInterceptor()),
+			TLSConfig: tlsConfig,
+		})
+	}
+
+	if s.conf.MetricsPort != 0 {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		httpServers = append(httpServers, &http.Server{
+			Addr:    fmt.Sprintf(":%d", s.conf.MetricsPort),
+			Handler: mux,
+		})
+	}
+
+	s.mu.Lock()
+	s.grpcServer = server
+	s.httpServers = httpServers
+	s.mu.Unlock()
+
+	go s.checkHealth()
+	go s.purge()
+
+	errc := make(chan error, len(httpServers)+1)
+	for _, httpServer := range httpServers {
+		go func(httpServer *http.Server) {
+			var err error
+			if httpServer.TLSConfig == nil {
+				err = httpServer.ListenAndServe()
+			} else {
+				err = httpServer.ListenAndServeTLS("", "")
+			}
+
+			if err == http.ErrServerClosed {
+				err = nil
+			}
+
+			errc <- err
+		}(httpServer)
+	}
+
+	go func() {
+		errc <- server.Serve(lis)
+	}()
+
+	return <-errc
+}
+
+// Shutdown marks service not serving, stops accepting requests and waits up to ShutdownTimeout
+// for running ones to finish, then cancels the rest and closes database
+func (s *Server) Shutdown() error {
+	s.healthMu.Lock()
+	close(s.done)
+	s.setServingStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+	s.healthMu.Unlock()
+
+	s.mu.Lock()
+	grpcServer, httpServers := s.grpcServer, s.httpServers
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.conf.ShutdownTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, httpServer := range httpServers {
+		wg.Add(1)
+		go func(httpServer *http.Server) {
+			defer wg.Done()
+			if err := httpServer.Shutdown(ctx); err != nil {
+				httpServer.Close()
+			}
+		}(httpServer)
+	}
+
+	if grpcServer != nil {
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			s.conf.Logger.Warn("shutdown timeout exceeded, cancelling running requests")
+			grpcServer.Stop()
+		}
+	}
+
+	wg.Wait()
+	return s.db.close()
+}
+
+// purge periodically removes expired idempotency keys and posts deleted more than PurgeRetention ago
+func (s *Server) purge() {
+	ticker := time.NewTicker(s.conf.PurgeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.done:
+			return
+		}
+
+		n, err := s.db.purgeIdempotencyKeys(context.Background(), time.Now().Add(-s.conf.IdempotencyKeyTTL))
+		if err != nil {
+			s.conf.Logger.WithError(err).Error("purging idempotency keys failed")
+		} else if n > 0 {
+			s.conf.Logger.WithField("count", n).Info("purged idempotency keys")
+		}
+
+		if s.conf.PurgeRetention == 0 {
+			continue
+		}
+
+		n, err = s.db.purgeDeleted(context.Background(), time.Now().Add(-s.conf.PurgeRetention))
+		if err != nil {
+			s.conf.Logger.WithError(err).Error("purging deleted posts failed")
+		} else if n > 0 {
+			s.conf.Logger.WithField("count", n).Info("purged deleted posts")
+		}
+	}
 }
@@ -8,78 +8,175 @@ import (
 	pb "github.com/andreymgn/RSOI-post/pkg/post/proto"
 	"github.com/google/uuid"
 	_ "github.com/lib/pq"
+	opentracing "github.com/opentracing/opentracing-go"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"golang.org/x/net/context"
+	"google.golang.org/genproto/protobuf/field_mask"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 )
 
 var (
-	errDummy     = errors.New("dummy")
-	dummyUID     = uuid.New()
-	nilUIDString = uuid.Nil.String()
+	errDummy      = errors.New("dummy")
+	dummyUID      = uuid.New()
+	nilUIDString  = uuid.Nil.String()
+	testLogger, _ = logtest.NewNullLogger()
+	testConfig    = Config{MaxBodyLength: 10, DefaultPageSize: DefaultPageSize, MaxPageSize: DefaultMaxPageSize, Logger: testLogger}
 )
 
-type mockdb struct{}
+type mockdb struct {
+	// keys maps idempotency keys to created posts
+	keys map[string]*Post
+	// down makes database pings fail
+	down bool
+}
+
+func (mdb *mockdb) ping(ctx context.Context) error {
+	if mdb.down {
+		return errDummy
+	}
+
+	return nil
+}
 
-func (mdb *mockdb) getAllPosts(pageSize, pageNumber int32) ([]*Post, error) {
+func (mdb *mockdb) checkSchema(ctx context.Context) error {
+	return nil
+}
+
+func (mdb *mockdb) close() error {
+	return nil
+}
+
+func (mdb *mockdb) getAllPosts(ctx context.Context, q listQuery) ([]*Post, error) {
 	result := make([]*Post, 0)
 	uid1 := uuid.New()
 	uid2 := uuid.New()
 	uid3 := uuid.New()
 
-	result = append(result, &Post{uid1, uid2, uid3, "First post", "google.com", time.Now(), time.Now()})
-	result = append(result, &Post{uid2, uid3, uid3, "Second post", "", time.Now(), time.Now().Add(time.Second * 10)})
-	result = append(result, &Post{uid3, uid1, uid1, "Third post", "yandex.ru", time.Now(), time.Now()})
+	result = append(result, &Post{UID: uid1, UserUID: uid2, CategoryUID: uid3, Title: "First post", URL: "google.com", CreatedAt: time.Now(), ModifiedAt: time.Now()})
+	result = append(result, &Post{UID: uid2, UserUID: uid3, CategoryUID: uid3, Title: "Second post", URL: "", CreatedAt: time.Now(), ModifiedAt: time.Now().Add(time.Second * 10)})
+	result = append(result, &Post{UID: uid3, UserUID: uid1, CategoryUID: uid1, Title: "Third post", URL: "yandex.ru", CreatedAt: time.Now(), ModifiedAt: time.Now()})
 	return result, nil
 }
 
-func (mdb *mockdb) getAllPostsByCategory(categoryUID uuid.UUID, pageSize, pageNumber int32) ([]*Post, error) {
+func (mdb *mockdb) getAllPostsByCategory(ctx context.Context, categoryUID uuid.UUID, q listQuery) ([]*Post, error) {
 	result := make([]*Post, 0)
 	uid1 := uuid.New()
 	uid2 := uuid.New()
 	uid3 := uuid.New()
 
-	result = append(result, &Post{uid1, uid2, categoryUID, "First post", "google.com", time.Now(), time.Now()})
-	result = append(result, &Post{uid2, uid3, categoryUID, "Second post", "", time.Now(), time.Now().Add(time.Second * 10)})
-	result = append(result, &Post{uid3, uid1, categoryUID, "Third post", "yandex.ru", time.Now(), time.Now()})
+	result = append(result, &Post{UID: uid1, UserUID: uid2, CategoryUID: categoryUID, Title: "First post", URL: "google.com", CreatedAt: time.Now(), ModifiedAt: time.Now()})
+	result = append(result, &Post{UID: uid2, UserUID: uid3, CategoryUID: categoryUID, Title: "Second post", URL: "", CreatedAt: time.Now(), ModifiedAt: time.Now().Add(time.Second * 10)})
+	result = append(result, &Post{UID: uid3, UserUID: uid1, CategoryUID: categoryUID, Title: "Third post", URL: "yandex.ru", CreatedAt: time.Now(), ModifiedAt: time.Now()})
 	return result, nil
 }
 
-func (mdb *mockdb) getOnePost(uid uuid.UUID) (*Post, error) {
+func (mdb *mockdb) getOnePost(ctx context.Context, uid uuid.UUID, includeDeleted bool) (*Post, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	if uid == uuid.Nil {
 		uid := uuid.New()
 
-		return &Post{uid, uid, uid, "First post", "google.com", time.Now(), time.Now()}, nil
+		return &Post{UID: uid, UserUID: uid, CategoryUID: uid, Title: "First post", URL: "google.com", CreatedAt: time.Now(), ModifiedAt: time.Now()}, nil
 	}
 
 	return nil, errDummy
 }
 
-func (mdb *mockdb) createPost(title, url string, userUID, categoryUID uuid.UUID) (*Post, error) {
-	if title == "success" {
+func (mdb *mockdb) createPost(ctx context.Context, p *Post, idempotencyKey string, keyTTL time.Duration) (*Post, bool, error) {
+	if post, ok := mdb.keys[idempotencyKey]; ok {
+		return post, false, nil
+	}
+
+	if p.Title == "success" {
 		uid := uuid.New()
 
-		return &Post{uid, userUID, categoryUID, "First post", "google.com", time.Now(), time.Now()}, nil
+		post := &Post{UID: uid, UserUID: p.UserUID, CategoryUID: p.CategoryUID, Title: "First post", URL: p.URL, Body: p.Body, Kind: p.Kind, CreatedAt: time.Now(), ModifiedAt: time.Now()}
+		if idempotencyKey != "" {
+			if mdb.keys == nil {
+				mdb.keys = make(map[string]*Post)
+			}
+
+			mdb.keys[idempotencyKey] = post
+		}
+
+		return post, true, nil
 	}
 
-	return nil, errDummy
+	return nil, false, errDummy
+}
+
+func (mdb *mockdb) updatePost(ctx context.Context, uid uuid.UUID, u postUpdate, expectedVersion int64) (int64, error) {
+	if uid == uuid.Nil {
+		if expectedVersion != 0 && expectedVersion != 1 {
+			return 0, errVersionMismatch
+		}
+
+		return 2, nil
+	}
+
+	return 0, errDummy
 }
 
-func (mdb *mockdb) updatePost(uid uuid.UUID, title, url string) error {
+func (mdb *mockdb) deletePost(ctx context.Context, uid uuid.UUID, expectedVersion int64) error {
 	if uid == uuid.Nil {
+		if expectedVersion != 0 && expectedVersion != 1 {
+			return errVersionMismatch
+		}
+
 		return nil
 	}
 
 	return errDummy
 }
 
-func (mdb *mockdb) deletePost(uid uuid.UUID) error {
+func (mdb *mockdb) restorePost(ctx context.Context, uid uuid.UUID) error {
 	if uid == uuid.Nil {
 		return nil
 	}
 
-	return errDummy
+	return errNotFound
+}
+
+func (mdb *mockdb) purgePost(ctx context.Context, uid uuid.UUID) error {
+	if uid == uuid.Nil {
+		return nil
+	}
+
+	return errNotFound
+}
+
+func (mdb *mockdb) purgeDeleted(ctx context.Context, t time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (mdb *mockdb) purgeIdempotencyKeys(ctx context.Context, t time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (mdb *mockdb) listRevisions(ctx context.Context, uid uuid.UUID, pageSize, pageNumber int32) ([]*Revision, error) {
+	if uid == uuid.Nil {
+		result := make([]*Revision, 0)
+		result = append(result, &Revision{uid, 2, "Edited post", "google.com", "", time.Now()})
+		result = append(result, &Revision{uid, 1, "First post", "google.com", "", time.Now().Add(-time.Hour)})
+		return result, nil
+	}
+
+	return nil, errNotFound
+}
+
+func (mdb *mockdb) getRevision(ctx context.Context, uid uuid.UUID, number int32) (*Revision, error) {
+	if uid == uuid.Nil && number == 1 {
+		return &Revision{uid, 1, "First post", "google.com", "", time.Now()}, nil
+	}
+
+	return nil, errNotFound
 }
 
-func (mdb *mockdb) checkPostExists(uid uuid.UUID) (bool, error) {
+func (mdb *mockdb) checkPostExists(ctx context.Context, uid uuid.UUID) (bool, error) {
 	if uid == uuid.Nil {
 		return true, nil
 	}
@@ -87,12 +184,44 @@ func (mdb *mockdb) checkPostExists(uid uuid.UUID) (bool, error) {
 	return false, errDummy
 }
 
-func (mdb *mockdb) getPostOwner(uid uuid.UUID) (string, error) {
+func (mdb *mockdb) getPostOwner(ctx context.Context, uid uuid.UUID) (string, error) {
 	return nilUIDString, nil
 }
 
+func (mdb *mockdb) vote(ctx context.Context, postUID, userUID uuid.UUID, value int) (int32, int32, error) {
+	if postUID == uuid.Nil {
+		if value > 0 {
+			return 1, 0, nil
+		}
+
+		return 0, 1, nil
+	}
+
+	return 0, 0, errNotFound
+}
+
+func (mdb *mockdb) removeVote(ctx context.Context, postUID, userUID uuid.UUID) (int32, int32, error) {
+	if postUID == uuid.Nil {
+		return 0, 0, nil
+	}
+
+	return 0, 0, errNotFound
+}
+
+func (mdb *mockdb) searchPosts(ctx context.Context, query string, categoryUID, userUID uuid.UUID, pageSize, pageNumber int32) ([]*SearchResult, error) {
+	if query == "fail" {
+		return nil, errDummy
+	}
+
+	result := make([]*SearchResult, 0)
+	uid := uuid.New()
+	post := &Post{UID: uid, UserUID: uid, CategoryUID: uid, Title: "First post", URL: "google.com", CreatedAt: time.Now(), ModifiedAt: time.Now()}
+	result = append(result, &SearchResult{post, 0.5, "<b>First</b> post"})
+	return result, nil
+}
+
 func TestListPosts(t *testing.T) {
-	s := &Server{&mockdb{}}
+	s := &Server{db: &mockdb{}, conf: testConfig}
 	var pageSize int32 = 3
 	req := &pb.ListPostsRequest{PageSize: pageSize, PageNumber: 1}
 	res, err := s.ListPosts(context.Background(), req)
@@ -106,7 +235,7 @@ func TestListPosts(t *testing.T) {
 }
 
 func TestListPostsByCategory(t *testing.T) {
-	s := &Server{&mockdb{}}
+	s := &Server{db: &mockdb{}, conf: testConfig}
 	var pageSize int32 = 3
 	req := &pb.ListPostsByCategoryRequest{CategoryUid: nilUIDString, PageSize: pageSize, PageNumber: 1}
 	res, err := s.ListPostsByCategory(context.Background(), req)
@@ -119,8 +248,115 @@ func TestListPostsByCategory(t *testing.T) {
 	}
 }
 
+func TestListPostsPageToken(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.ListPostsRequest{PageSize: 3}
+	res, err := s.ListPosts(context.Background(), req)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if res.NextPageToken == "" {
+		t.Errorf("expected next page token on full page")
+	}
+
+	req = &pb.ListPostsRequest{PageSize: 3, PageToken: res.NextPageToken}
+	_, err = s.ListPosts(context.Background(), req)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	req = &pb.ListPostsRequest{PageSize: 4}
+	res, err = s.ListPosts(context.Background(), req)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if res.NextPageToken != "" {
+		t.Errorf("unexpected next page token on last page")
+	}
+}
+
+func TestListPostsPageTokenFail(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.ListPostsRequest{PageToken: "not a token"}
+	_, err := s.ListPosts(context.Background(), req)
+	if err != statusInvalidPageToken {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestListPostsSort(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.ListPostsRequest{PageSize: 3, Sort: pb.SortOrder_TOP, Window: pb.TimeWindow_WEEK}
+	res, err := s.ListPosts(context.Background(), req)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	req = &pb.ListPostsRequest{PageSize: 3, Sort: pb.SortOrder_TOP, PageToken: res.NextPageToken}
+	_, err = s.ListPosts(context.Background(), req)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	req = &pb.ListPostsRequest{PageSize: 3, Sort: pb.SortOrder_HOT, PageToken: res.NextPageToken}
+	_, err = s.ListPosts(context.Background(), req)
+	if err != statusInvalidPageToken {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	req = &pb.ListPostsRequest{Sort: pb.SortOrder(42)}
+	_, err = s.ListPosts(context.Background(), req)
+	if !violates(err, "sort") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestHotRank(t *testing.T) {
+	now := time.Now()
+	if hotRank(10, now) <= hotRank(1, now) {
+		t.Errorf("expected higher score to rank higher")
+	}
+
+	if hotRank(1, now) <= hotRank(1, now.Add(-time.Hour)) {
+		t.Errorf("expected newer post to rank higher")
+	}
+
+	if hotRank(-10, now) >= hotRank(0, now) {
+		t.Errorf("expected negative score to rank lower")
+	}
+}
+
+func TestControversy(t *testing.T) {
+	if controversy(10, 0) != 0 {
+		t.Errorf("expected one-sided votes not to be controversial")
+	}
+
+	if controversy(10, 10) <= controversy(10, 2) {
+		t.Errorf("expected even split to be more controversial")
+	}
+}
+
+func TestPageToken(t *testing.T) {
+	post := &Post{UID: uuid.New(), CreatedAt: time.Now()}
+	token, err := cursorAfter(post, sortNew).encode()
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	cursor, err := decodePageToken(token)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if cursor.UID != post.UID || !cursor.CreatedAt.Equal(post.CreatedAt) {
+		t.Errorf("unexpected cursor: got %v want %v", cursor, cursorAfter(post, sortNew))
+	}
+}
+
 func TestGetPost(t *testing.T) {
-	s := &Server{&mockdb{}}
+	s := &Server{db: &mockdb{}, conf: testConfig}
 	req := &pb.GetPostRequest{Uid: nilUIDString}
 	_, err := s.GetPost(context.Background(), req)
 	if err != nil {
@@ -129,7 +365,7 @@ func TestGetPost(t *testing.T) {
 }
 
 func TestGetPostFail(t *testing.T) {
-	s := &Server{&mockdb{}}
+	s := &Server{db: &mockdb{}, conf: testConfig}
 	req := &pb.GetPostRequest{Uid: ""}
 	_, err := s.GetPost(context.Background(), req)
 	if err == nil {
@@ -137,8 +373,25 @@ func TestGetPostFail(t *testing.T) {
 	}
 }
 
+func TestGetPostContextDone(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.GetPostRequest{Uid: nilUIDString}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.GetPost(ctx, req); status.Code(err) != codes.Canceled {
+		t.Errorf("canceled request: got %v want %v", err, codes.Canceled)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	if _, err := s.GetPost(ctx, req); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("expired request: got %v want %v", err, codes.DeadlineExceeded)
+	}
+}
+
 func TestCreatePost(t *testing.T) {
-	s := &Server{&mockdb{}}
+	s := &Server{db: &mockdb{}, conf: testConfig}
 	req := &pb.CreatePostRequest{CategoryUid: nilUIDString, Title: "success", UserUid: nilUIDString}
 	_, err := s.CreatePost(context.Background(), req)
 	if err != nil {
@@ -147,11 +400,11 @@ func TestCreatePost(t *testing.T) {
 }
 
 func TestCreatePostFail(t *testing.T) {
-	s := &Server{&mockdb{}}
+	s := &Server{db: &mockdb{}, conf: testConfig}
 
 	req := &pb.CreatePostRequest{Title: ""}
 	_, err := s.CreatePost(context.Background(), req)
-	if err != statusNoPostTitle {
+	if !violates(err, "title") {
 		t.Errorf("unexpected error %v", err)
 	}
 
@@ -162,8 +415,60 @@ func TestCreatePostFail(t *testing.T) {
 	}
 }
 
+func TestCreatePostIdempotent(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.CreatePostRequest{CategoryUid: nilUIDString, Title: "success", UserUid: nilUIDString, IdempotencyKey: "key"}
+	first, err := s.CreatePost(context.Background(), req)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	second, err := s.CreatePost(context.Background(), req)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if first.Uid != second.Uid {
+		t.Errorf("expected retry to return the same post: got %v want %v", second.Uid, first.Uid)
+	}
+}
+
+func TestCreateTextPost(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.CreatePostRequest{CategoryUid: nilUIDString, Title: "success", UserUid: nilUIDString, Kind: pb.PostKind_TEXT, Body: "text"}
+	res, err := s.CreatePost(context.Background(), req)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if res.Kind != pb.PostKind_TEXT || res.Body != "text" {
+		t.Errorf("unexpected post: got %v", res)
+	}
+}
+
+func TestCreateTextPostFail(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.CreatePostRequest{CategoryUid: nilUIDString, Title: "success", UserUid: nilUIDString, Kind: pb.PostKind_TEXT, Body: "too long post body"}
+	_, err := s.CreatePost(context.Background(), req)
+	if !violates(err, "body") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	req = &pb.CreatePostRequest{CategoryUid: nilUIDString, Title: "success", UserUid: nilUIDString, Kind: pb.PostKind_TEXT, Url: "google.com"}
+	_, err = s.CreatePost(context.Background(), req)
+	if !violates(err, "url") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	req = &pb.CreatePostRequest{CategoryUid: nilUIDString, Title: "success", UserUid: nilUIDString, Body: "text"}
+	_, err = s.CreatePost(context.Background(), req)
+	if !violates(err, "body") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
 func TestUpdatePost(t *testing.T) {
-	s := &Server{&mockdb{}}
+	s := &Server{db: &mockdb{}, conf: testConfig}
 	req := &pb.UpdatePostRequest{Uid: nilUIDString}
 	_, err := s.UpdatePost(context.Background(), req)
 	if err != nil {
@@ -172,16 +477,81 @@ func TestUpdatePost(t *testing.T) {
 }
 
 func TestUpdatePostFail(t *testing.T) {
-	s := &Server{&mockdb{}}
+	s := &Server{db: &mockdb{}, conf: testConfig}
 	req := &pb.UpdatePostRequest{Uid: ""}
 	_, err := s.UpdatePost(context.Background(), req)
 	if err == nil {
 		t.Errorf("expected error, got nothing")
 	}
+
+	req = &pb.UpdatePostRequest{Uid: nilUIDString, Body: "text"}
+	_, err = s.UpdatePost(context.Background(), req)
+	if !violates(err, "body") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestUpdatePostVersion(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.UpdatePostRequest{Uid: nilUIDString, Title: "Edited", ExpectedVersion: 1}
+	res, err := s.UpdatePost(context.Background(), req)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if res.Version != 2 {
+		t.Errorf("unexpected version: got %v want %v", res.Version, 2)
+	}
+
+	req.ExpectedVersion = 5
+	_, err = s.UpdatePost(context.Background(), req)
+	if err != statusVersionMismatch {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestUpdatePostMask(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.UpdatePostRequest{Uid: nilUIDString, UpdateMask: &field_mask.FieldMask{Paths: []string{"url"}}}
+	_, err := s.UpdatePost(context.Background(), req)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	var v fieldViolations
+	u := newPostUpdate(&v, req)
+	if len(v) != 0 {
+		t.Errorf("unexpected violations %v", v)
+	}
+
+	if u.url == nil || *u.url != "" || u.title != nil || u.body != nil {
+		t.Errorf("unexpected update: got %v", u)
+	}
+
+	req.UpdateMask.Paths = []string{"title"}
+	_, err = s.UpdatePost(context.Background(), req)
+	if !violates(err, "title") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	req.UpdateMask.Paths = []string{"uid"}
+	_, err = s.UpdatePost(context.Background(), req)
+	if !violates(err, "updateMask") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestDeletePostVersion(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.DeletePostRequest{Uid: nilUIDString, ExpectedVersion: 5}
+	_, err := s.DeletePost(context.Background(), req)
+	if err != statusVersionMismatch {
+		t.Errorf("unexpected error %v", err)
+	}
 }
 
 func TestDeletePost(t *testing.T) {
-	s := &Server{&mockdb{}}
+	s := &Server{db: &mockdb{}, conf: testConfig}
 	req := &pb.DeletePostRequest{Uid: nilUIDString}
 	_, err := s.DeletePost(context.Background(), req)
 	if err != nil {
@@ -190,7 +560,7 @@ func TestDeletePost(t *testing.T) {
 }
 
 func TestDeletePostFail(t *testing.T) {
-	s := &Server{&mockdb{}}
+	s := &Server{db: &mockdb{}, conf: testConfig}
 	req := &pb.DeletePostRequest{Uid: ""}
 	_, err := s.DeletePost(context.Background(), req)
 	if err == nil {
@@ -198,8 +568,84 @@ func TestDeletePostFail(t *testing.T) {
 	}
 }
 
+func TestRestorePost(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.RestorePostRequest{Uid: nilUIDString}
+	_, err := s.RestorePost(context.Background(), req)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestRestorePostFail(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.RestorePostRequest{Uid: dummyUID.String()}
+	_, err := s.RestorePost(context.Background(), req)
+	if err != statusNotFound {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestPurgePost(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.PurgePostRequest{Uid: nilUIDString}
+	_, err := s.PurgePost(context.Background(), req)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestPurgePostFail(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.PurgePostRequest{Uid: ""}
+	_, err := s.PurgePost(context.Background(), req)
+	if err == nil {
+		t.Errorf("expected error, got nothing")
+	}
+}
+
+func TestListPostRevisions(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.ListPostRevisionsRequest{Uid: nilUIDString}
+	res, err := s.ListPostRevisions(context.Background(), req)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if len(res.Revisions) != 2 {
+		t.Errorf("unexpected number of revisions: got %v want %v", len(res.Revisions), 2)
+	}
+}
+
+func TestListPostRevisionsFail(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.ListPostRevisionsRequest{Uid: dummyUID.String()}
+	_, err := s.ListPostRevisions(context.Background(), req)
+	if err != statusNotFound {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestGetPostRevision(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.GetPostRevisionRequest{Uid: nilUIDString, Revision: 1}
+	_, err := s.GetPostRevision(context.Background(), req)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestGetPostRevisionFail(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.GetPostRevisionRequest{Uid: nilUIDString, Revision: 3}
+	_, err := s.GetPostRevision(context.Background(), req)
+	if err != statusRevisionNotFound {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
 func TestCheckPostExists(t *testing.T) {
-	s := &Server{&mockdb{}}
+	s := &Server{db: &mockdb{}, conf: testConfig}
 	req := &pb.CheckPostExistsRequest{Uid: nilUIDString}
 	_, err := s.CheckPostExists(context.Background(), req)
 	if err != nil {
@@ -208,7 +654,7 @@ func TestCheckPostExists(t *testing.T) {
 }
 
 func TestCheckPostExistsFail(t *testing.T) {
-	s := &Server{&mockdb{}}
+	s := &Server{db: &mockdb{}, conf: testConfig}
 	req := &pb.CheckPostExistsRequest{Uid: ""}
 	_, err := s.CheckPostExists(context.Background(), req)
 	if err == nil {
@@ -217,7 +663,7 @@ func TestCheckPostExistsFail(t *testing.T) {
 }
 
 func TestGetPostOwner(t *testing.T) {
-	s := &Server{&mockdb{}}
+	s := &Server{db: &mockdb{}, conf: testConfig}
 	req := &pb.GetPostOwnerRequest{Uid: nilUIDString}
 	_, err := s.GetPostOwner(context.Background(), req)
 	if err != nil {
@@ -226,10 +672,168 @@ func TestGetPostOwner(t *testing.T) {
 }
 
 func TestGetPostOwnerFail(t *testing.T) {
-	s := &Server{&mockdb{}}
+	s := &Server{db: &mockdb{}, conf: testConfig}
 	req := &pb.GetPostOwnerRequest{Uid: ""}
 	_, err := s.GetPostOwner(context.Background(), req)
 	if err == nil {
 		t.Errorf("expected error, got nothing")
 	}
 }
+
+func TestVote(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.VoteRequest{PostUid: nilUIDString, UserUid: nilUIDString, Direction: pb.VoteDirection_UP}
+	res, err := s.Vote(context.Background(), req)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if res.Score != 1 {
+		t.Errorf("unexpected score: got %v want %v", res.Score, 1)
+	}
+
+	req.Direction = pb.VoteDirection_DOWN
+	res, err = s.Vote(context.Background(), req)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if res.Score != -1 {
+		t.Errorf("unexpected score: got %v want %v", res.Score, -1)
+	}
+}
+
+func TestVoteFail(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.VoteRequest{PostUid: nilUIDString, UserUid: nilUIDString}
+	_, err := s.Vote(context.Background(), req)
+	if !violates(err, "direction") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	req = &pb.VoteRequest{PostUid: dummyUID.String(), UserUid: nilUIDString, Direction: pb.VoteDirection_UP}
+	_, err = s.Vote(context.Background(), req)
+	if err != statusNotFound {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestRemoveVote(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.RemoveVoteRequest{PostUid: nilUIDString, UserUid: nilUIDString}
+	_, err := s.RemoveVote(context.Background(), req)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestRemoveVoteFail(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.RemoveVoteRequest{PostUid: "", UserUid: nilUIDString}
+	_, err := s.RemoveVote(context.Background(), req)
+	if err == nil {
+		t.Errorf("expected error, got nothing")
+	}
+}
+
+func TestSearchPosts(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.SearchPostsRequest{Query: "first", CategoryUid: nilUIDString}
+	res, err := s.SearchPosts(context.Background(), req)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if len(res.Results) != 1 {
+		t.Errorf("unexpected number of results: got %v want %v", len(res.Results), 1)
+	}
+}
+
+func TestSearchPostsFail(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.SearchPostsRequest{Query: "  "}
+	_, err := s.SearchPosts(context.Background(), req)
+	if !violates(err, "query") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	req = &pb.SearchPostsRequest{Query: "first", UserUid: "invalid"}
+	_, err = s.SearchPosts(context.Background(), req)
+	if !violates(err, "userUid") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	req = &pb.SearchPostsRequest{Query: "fail"}
+	_, err = s.SearchPosts(context.Background(), req)
+	if err == nil {
+		t.Errorf("expected error, got nothing")
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: Config{ShutdownTimeout: time.Second}, health: newHealthServer(), done: make(chan struct{})}
+	s.setServingStatus(healthpb.HealthCheckResponse_SERVING)
+	if err := s.Shutdown(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	resp, err := s.health.Check(context.Background(), &healthpb.HealthCheckRequest{Service: serviceName})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if resp.Status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("unexpected status after shutdown %v", resp.Status)
+	}
+
+	s.updateHealth()
+	resp, _ = s.health.Check(context.Background(), &healthpb.HealthCheckRequest{Service: serviceName})
+	if resp.Status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("status changed after shutdown to %v", resp.Status)
+	}
+}
+
+func TestStartShutdown(t *testing.T) {
+	conf := Config{TLSMode: TLSInsecure, PurgeInterval: time.Hour, HealthCheckInterval: time.Hour, ShutdownTimeout: time.Second}
+	s := &Server{db: &mockdb{}, conf: conf, health: newHealthServer(), done: make(chan struct{})}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.Start(0, opentracing.NoopTracer{})
+	}()
+
+	for started := false; !started; time.Sleep(time.Millisecond) {
+		s.mu.Lock()
+		started = s.grpcServer != nil
+		s.mu.Unlock()
+	}
+
+	if err := s.Shutdown(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Start did not return after Shutdown")
+	}
+}
+
+func TestPageSize(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	tests := []struct {
+		requested int32
+		want      int32
+	}{
+		{0, DefaultPageSize},
+		{5, 5},
+	}
+
+	for _, test := range tests {
+		if got := s.pageSize(test.requested); got != test.want {
+			t.Errorf("pageSize(%d): got %d want %d", test.requested, got, test.want)
+		}
+	}
+}
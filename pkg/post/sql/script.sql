@@ -1,11 +0,0 @@
-CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
-
-CREATE TABLE posts (
-    uid UUID PRIMARY KEY,
-    user_uid UUID NOT NULL,
-    category_uid UUID NOT NULL,
-    title VARCHAR(80) NOT NULL,
-    url VARCHAR(80),
-    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
-    modified_at TIMESTAMP WITH TIME ZONE NOT NULL
-);
\ No newline at end of file
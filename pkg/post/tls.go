@@ -0,0 +1,95 @@
+package post
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// TLSMode defines how service listeners are secured
+type TLSMode string
+
+const (
+	// TLSServer serves TLS without verifying clients
+	TLSServer TLSMode = "tls"
+	// TLSMutual requires client certificate signed by one of Config.ClientCAFile certificates
+	TLSMutual TLSMode = "mtls"
+	// TLSInsecure serves plaintext, for development only
+	TLSInsecure TLSMode = "insecure"
+)
+
+var errNoClientCA = errors.New("client CA file is required in mtls mode")
+
+// tlsConfig returns TLS config of service listeners, nil in insecure mode
+func (c Config) tlsConfig() (*tls.Config, error) {
+	switch c.TLSMode {
+	case TLSInsecure:
+		return nil, nil
+	case TLSServer, TLSMutual:
+	default:
+		return nil, fmt.Errorf("unknown TLS mode %q", c.TLSMode)
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	conf := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if c.TLSMode != TLSMutual {
+		return conf, nil
+	}
+
+	if c.ClientCAFile == "" {
+		return nil, errNoClientCA
+	}
+
+	pem, err := ioutil.ReadFile(c.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", c.ClientCAFile)
+	}
+
+	conf.ClientCAs = pool
+	conf.ClientAuth = tls.RequireAndVerifyClientCert
+	return conf, nil
+}
+
+type identityKey struct{}
+
+// clientIdentity returns common name of verified client certificate, empty unless mTLS is used
+func clientIdentity(ctx context.Context) string {
+	identity, _ := ctx.Value(identityKey{}).(string)
+	return identity
+}
+
+// withClientIdentity returns ctx carrying identity of client verified in TLS handshake
+func withClientIdentity(ctx context.Context, state *tls.ConnectionState) context.Context {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return ctx
+	}
+
+	return context.WithValue(ctx, identityKey{}, state.VerifiedChains[0][0].Subject.CommonName)
+}
+
+// identityInterceptor makes verified client identity available to handlers
+func identityInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			ctx = withClientIdentity(ctx, &tlsInfo.State)
+		}
+	}
+
+	return handler(ctx, req)
+}
@@ -0,0 +1,70 @@
+package post
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+func TestTLSConfig(t *testing.T) {
+	conf, err := Config{TLSMode: TLSInsecure}.tlsConfig()
+	if err != nil || conf != nil {
+		t.Errorf("insecure mode: unexpected result %v %v", conf, err)
+	}
+
+	if _, err := (Config{TLSMode: "none"}).tlsConfig(); err == nil {
+		t.Errorf("unknown mode: expected error")
+	}
+
+	if _, err := (Config{TLSMode: TLSServer, CertFile: "/nonexistent.pem", KeyFile: "/nonexistent.pem"}).tlsConfig(); err == nil {
+		t.Errorf("missing certificate: expected error")
+	}
+}
+
+func verifiedState(commonName string) *tls.ConnectionState {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+	return &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+}
+
+func TestIdentityInterceptor(t *testing.T) {
+	var identity string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		identity = clientIdentity(ctx)
+		return nil, nil
+	}
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: *verifiedState("gateway")}})
+	identityInterceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	if identity != "gateway" {
+		t.Errorf("unexpected identity: got %q want %q", identity, "gateway")
+	}
+
+	identityInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+	if identity != "" {
+		t.Errorf("unexpected identity without TLS: got %q", identity)
+	}
+}
+
+func TestHTTPPeerIdentity(t *testing.T) {
+	var identity string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		identity = clientIdentity(ctx)
+		return nil, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/posts", nil)
+	req.TLS = verifiedState("gateway")
+	ctx := peer.NewContext(context.Background(), httpPeer(req))
+	identityInterceptor(ctx, req, &grpc.UnaryServerInfo{}, handler)
+	if identity != "gateway" {
+		t.Errorf("unexpected identity: got %q want %q", identity, "gateway")
+	}
+}
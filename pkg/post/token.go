@@ -0,0 +1,54 @@
+package post
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var errInvalidPageToken = errors.New("invalid page token")
+
+// pageCursor points to the last post of a page, next page starts right after it
+type pageCursor struct {
+	Sort      sortOrder `json:"s,omitempty"`
+	Rank      float64   `json:"r,omitempty"`
+	CreatedAt time.Time `json:"t"`
+	UID       uuid.UUID `json:"u"`
+}
+
+// cursorAfter returns cursor pointing to post p in list sorted by o
+func cursorAfter(p *Post, o sortOrder) *pageCursor {
+	return &pageCursor{o, o.rank(p), p.CreatedAt, p.UID}
+}
+
+// encode returns opaque page token
+func (c *pageCursor) encode() (string, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodePageToken parses token returned by encode
+func decodePageToken(token string) (*pageCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errInvalidPageToken
+	}
+
+	c := new(pageCursor)
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, errInvalidPageToken
+	}
+
+	if c.CreatedAt.IsZero() || c.UID == uuid.Nil {
+		return nil, errInvalidPageToken
+	}
+
+	return c, nil
+}
@@ -0,0 +1,95 @@
+package post
+
+import (
+	"database/sql"
+	"strings"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"golang.org/x/net/context"
+)
+
+// dbCallKey is context key of dbCall being traced
+type dbCallKey struct{}
+
+// dbCall collects SQL statements of datastore call and number of rows they returned or affected
+type dbCall struct {
+	statements []string
+	rows       int64
+}
+
+// startDBSpan starts span of datastore method as child of span in ctx, nothing is traced without parent span.
+// Returned function finishes span with error returned by method
+func startDBSpan(ctx context.Context, method string) (context.Context, func(error)) {
+	parent := opentracing.SpanFromContext(ctx)
+	if parent == nil {
+		return ctx, func(error) {}
+	}
+
+	span := parent.Tracer().StartSpan("db."+method, opentracing.ChildOf(parent.Context()), ext.SpanKindRPCClient)
+	ext.Component.Set(span, "database/sql")
+	ext.DBType.Set(span, "postgresql")
+
+	call := new(dbCall)
+	ctx = context.WithValue(opentracing.ContextWithSpan(ctx, span), dbCallKey{}, call)
+	return ctx, func(err error) {
+		// statements use placeholders, so parameters never get into traces
+		ext.DBStatement.Set(span, strings.Join(call.statements, "; "))
+		span.SetTag("db.rows", call.rows)
+		if !expectedError(err) {
+			ext.Error.Set(span, true)
+			span.LogFields(log.Error(err))
+		}
+
+		span.Finish()
+	}
+}
+
+// traceStatement records SQL statement executed by traced datastore call
+func traceStatement(ctx context.Context, query string) {
+	if call, ok := ctx.Value(dbCallKey{}).(*dbCall); ok {
+		call.statements = append(call.statements, query)
+	}
+}
+
+// traceRows records number of rows returned or affected by traced datastore call
+func traceRows(ctx context.Context, n int64) {
+	if call, ok := ctx.Value(dbCallKey{}).(*dbCall); ok {
+		call.rows += n
+	}
+}
+
+// queryer is implemented by sql.DB, sql.Conn and sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// queryContext runs traced query, caller records number of returned rows
+func queryContext(ctx context.Context, q queryer, query string, args ...interface{}) (*sql.Rows, error) {
+	traceStatement(ctx, query)
+	return q.QueryContext(ctx, query, args...)
+}
+
+// queryRowContext runs traced query returning at most one row
+func queryRowContext(ctx context.Context, q queryer, query string, args ...interface{}) *sql.Row {
+	traceStatement(ctx, query)
+	return q.QueryRowContext(ctx, query, args...)
+}
+
+// execContext runs traced statement and records number of affected rows
+func execContext(ctx context.Context, q queryer, query string, args ...interface{}) (sql.Result, error) {
+	traceStatement(ctx, query)
+	result, err := q.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if n, err := result.RowsAffected(); err == nil {
+		traceRows(ctx, n)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,82 @@
+package post
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"golang.org/x/net/context"
+)
+
+// mockQueryer executes statements affecting rows rows
+type mockQueryer struct {
+	rows int64
+}
+
+func (q mockQueryer) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, errDummy
+}
+
+func (q mockQueryer) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return nil
+}
+
+func (q mockQueryer) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return driver.RowsAffected(q.rows), nil
+}
+
+func TestDBSpan(t *testing.T) {
+	tracer := mocktracer.New()
+	parent := tracer.StartSpan("GetPost")
+	ctx := opentracing.ContextWithSpan(context.Background(), parent)
+
+	ctx, finish := startDBSpan(ctx, "deletePost")
+	execContext(ctx, mockQueryer{2}, "DELETE FROM posts WHERE uid=$1", "secret")
+	execContext(ctx, mockQueryer{1}, "DELETE FROM votes WHERE post_uid=$1", "secret")
+	finish(errDummy)
+
+	spans := tracer.FinishedSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d finished spans want 1", len(spans))
+	}
+
+	span := spans[0]
+	if span.OperationName != "db.deletePost" || span.ParentID != parent.(*mocktracer.MockSpan).SpanContext.SpanID {
+		t.Errorf("unexpected span %v with parent %v", span.OperationName, span.ParentID)
+	}
+
+	want := map[string]interface{}{
+		"db.statement": "DELETE FROM posts WHERE uid=$1; DELETE FROM votes WHERE post_uid=$1",
+		"db.rows":      int64(3),
+		"db.type":      "postgresql",
+		"error":        true,
+	}
+	for tag, value := range want {
+		if got := span.Tag(tag); got != value {
+			t.Errorf("tag %s: got %v want %v", tag, got, value)
+		}
+	}
+}
+
+func TestDBSpanExpectedError(t *testing.T) {
+	tracer := mocktracer.New()
+	ctx := opentracing.ContextWithSpan(context.Background(), tracer.StartSpan("GetPost"))
+	_, finish := startDBSpan(ctx, "getOnePost")
+	finish(errNotFound)
+
+	spans := tracer.FinishedSpans()
+	if len(spans) != 1 || spans[0].Tag("error") != nil {
+		t.Errorf("missing post must not be traced as error: got %v", spans)
+	}
+}
+
+func TestDBSpanWithoutParent(t *testing.T) {
+	ctx := context.Background()
+	spanCtx, finish := startDBSpan(ctx, "getOnePost")
+	finish(nil)
+	if spanCtx != ctx {
+		t.Errorf("call without parent span must not be traced")
+	}
+}
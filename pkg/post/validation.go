@@ -0,0 +1,156 @@
+package post
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// maxTitleLength is size of title column
+	maxTitleLength = 80
+	// maxURLLength is size of url column
+	maxURLLength = 80
+	// maxIdempotencyKeyLength is size of idempotency key column
+	maxIdempotencyKeyLength = 128
+	// maxQueryLength limits search query, longer ones are too expensive to parse and match
+	maxQueryLength = 256
+)
+
+// fieldViolations collects problems with request fields, so client learns about all of them at once.
+// Fields are named as in proto definitions (camelCase), gRPC and HTTP clients see the same names
+type fieldViolations []*errdetails.BadRequest_FieldViolation
+
+// add records problem with field
+func (v *fieldViolations) add(field, description string) {
+	*v = append(*v, &errdetails.BadRequest_FieldViolation{Field: field, Description: description})
+}
+
+// err returns InvalidArgument status listing all violations, nil if there are none
+func (v fieldViolations) err() error {
+	if len(v) == 0 {
+		return nil
+	}
+
+	problems := make([]string, len(v))
+	for i, violation := range v {
+		problems[i] = violation.Field + " " + violation.Description
+	}
+
+	st := status.New(codes.InvalidArgument, "invalid request: "+strings.Join(problems, "; "))
+	withDetails, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: v})
+	if err != nil {
+		return st.Err()
+	}
+
+	return withDetails.Err()
+}
+
+// uid parses required UUID field
+func (v *fieldViolations) uid(field, value string) uuid.UUID {
+	uid, err := uuid.Parse(value)
+	if err != nil {
+		v.add(field, "must be a valid UUID")
+	}
+
+	return uid
+}
+
+// optionalUID parses UUID field, empty one is parsed as nil UUID
+func (v *fieldViolations) optionalUID(field, value string) uuid.UUID {
+	if value == "" {
+		return uuid.Nil
+	}
+
+	return v.uid(field, value)
+}
+
+// text checks that field is valid UTF-8 without control characters, not longer than max characters if max is positive
+func (v *fieldViolations) text(field, value string, max int) {
+	if !utf8.ValidString(value) {
+		v.add(field, "must be valid UTF-8")
+		return
+	}
+
+	if max > 0 && utf8.RuneCountInString(value) > max {
+		v.add(field, fmt.Sprintf("must be at most %d characters long", max))
+	}
+
+	if strings.IndexFunc(value, unicode.IsControl) != -1 {
+		v.add(field, "must not contain control characters")
+	}
+}
+
+// title checks post title
+func (v *fieldViolations) title(value string) {
+	if strings.TrimSpace(value) == "" {
+		v.add("title", "is required")
+		return
+	}
+
+	v.text("title", value, maxTitleLength)
+}
+
+// url checks post URL, it's optional but must be absolute HTTP or HTTPS URL if set
+func (v *fieldViolations) url(value string) {
+	if value == "" {
+		return
+	}
+
+	v.text("url", value, maxURLLength)
+	u, err := url.Parse(value)
+	switch {
+	case err != nil:
+		v.add("url", "must be a valid URL")
+	case u.Scheme != "http" && u.Scheme != "https":
+		v.add("url", "must have http or https scheme")
+	case u.Host == "":
+		v.add("url", "must have host")
+	}
+}
+
+// body checks text post body, unlike title it may contain line breaks and tabs
+func (s *Server) checkBody(v *fieldViolations, body string) {
+	if !utf8.ValidString(body) {
+		v.add("body", "must be valid UTF-8")
+		return
+	}
+
+	if s.bodyTooLong(body) {
+		v.add("body", fmt.Sprintf("must be at most %d characters long", s.conf.MaxBodyLength))
+	}
+
+	// Postgres can't store NUL in text
+	if strings.IndexByte(body, 0) != -1 {
+		v.add("body", "must not contain NUL characters")
+	}
+}
+
+// checkPage checks requested page and returns its size, see pageSize
+func (s *Server) checkPage(v *fieldViolations, size, number int32) int32 {
+	switch {
+	case size < 0:
+		v.add("pageSize", "must not be negative")
+	case size > s.conf.MaxPageSize:
+		v.add("pageSize", fmt.Sprintf("must be at most %d", s.conf.MaxPageSize))
+	}
+
+	pageSize := s.pageSize(size)
+	switch {
+	case number < 0:
+		v.add("pageNumber", "must not be negative")
+	case int64(number)*int64(pageSize) > math.MaxInt32:
+		// offset of page must fit query parameter
+		v.add("pageNumber", "is too large")
+	}
+
+	return pageSize
+}
@@ -0,0 +1,160 @@
+package post
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	pb "github.com/andreymgn/RSOI-post/pkg/post/proto"
+	"golang.org/x/net/context"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// violatedFields returns fields listed in BadRequest details of err
+func violatedFields(err error) []string {
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		return nil
+	}
+
+	var fields []string
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.FieldViolations {
+				fields = append(fields, violation.Field)
+			}
+		}
+	}
+
+	return fields
+}
+
+// violates checks if err reports violation of field
+func violates(err error, field string) bool {
+	for _, f := range violatedFields(err) {
+		if f == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestCreatePostViolations(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.CreatePostRequest{
+		Title:          strings.Repeat("a", maxTitleLength+1),
+		Url:            "ftp://example.com",
+		UserUid:        "invalid",
+		CategoryUid:    nilUIDString,
+		Kind:           pb.PostKind(42),
+		IdempotencyKey: strings.Repeat("k", maxIdempotencyKeyLength+1),
+	}
+
+	_, err := s.CreatePost(context.Background(), req)
+	got := violatedFields(err)
+	want := []string{"title", "url", "userUid", "kind", "idempotencyKey"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("unexpected violations: got %v want %v", got, want)
+	}
+
+	if msg := status.Convert(err).Message(); !strings.Contains(msg, "title must be at most 80 characters long") {
+		t.Errorf("message must list violations: got %q", msg)
+	}
+}
+
+func TestTitleViolations(t *testing.T) {
+	tests := []struct {
+		title string
+		valid bool
+	}{
+		{"title", true},
+		{"заголовок", true},
+		{strings.Repeat("я", maxTitleLength), true},
+		{strings.Repeat("я", maxTitleLength+1), false},
+		{"", false},
+		{" \t ", false},
+		{"line\nbreak", false},
+		{"nul\x00", false},
+		{"invalid \xff", false},
+	}
+
+	for _, tt := range tests {
+		var v fieldViolations
+		v.title(tt.title)
+		if valid := len(v) == 0; valid != tt.valid {
+			t.Errorf("title %q: got valid %v want %v", tt.title, valid, tt.valid)
+		}
+	}
+}
+
+func TestURLViolations(t *testing.T) {
+	tests := []struct {
+		url   string
+		valid bool
+	}{
+		{"", true},
+		{"https://example.com/post?id=1", true},
+		{"HTTP://example.com", true},
+		{"example.com", false},
+		{"javascript:alert(1)", false},
+		{"http://", false},
+		{"http://example.com/%zz", false},
+		{"https://example.com/" + strings.Repeat("a", maxURLLength), false},
+	}
+
+	for _, tt := range tests {
+		var v fieldViolations
+		v.url(tt.url)
+		if valid := len(v) == 0; valid != tt.valid {
+			t.Errorf("url %q: got valid %v want %v", tt.url, valid, tt.valid)
+		}
+	}
+}
+
+func TestUpdatePostViolations(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	req := &pb.UpdatePostRequest{Uid: nilUIDString, Title: strings.Repeat("a", maxTitleLength+1), Url: "not a url"}
+	_, err := s.UpdatePost(context.Background(), req)
+	if !violates(err, "title") || !violates(err, "url") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestPageViolations(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	_, err := s.ListPosts(context.Background(), &pb.ListPostsRequest{PageSize: -1, PageNumber: -1})
+	if !violates(err, "pageSize") || !violates(err, "pageNumber") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	_, err = s.ListPosts(context.Background(), &pb.ListPostsRequest{PageSize: DefaultMaxPageSize + 1})
+	if !violates(err, "pageSize") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	_, err = s.SearchPosts(context.Background(), &pb.SearchPostsRequest{Query: "first", PageNumber: math.MaxInt32})
+	if !violates(err, "pageNumber") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	_, err = s.ListPostRevisions(context.Background(), &pb.ListPostRevisionsRequest{Uid: nilUIDString, PageSize: -5})
+	if !violates(err, "pageSize") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	_, err = s.GetPostRevision(context.Background(), &pb.GetPostRevisionRequest{Uid: nilUIDString})
+	if !violates(err, "revision") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestSearchQueryViolations(t *testing.T) {
+	s := &Server{db: &mockdb{}, conf: testConfig}
+	_, err := s.SearchPosts(context.Background(), &pb.SearchPostsRequest{Query: strings.Repeat("a", maxQueryLength+1)})
+	if !violates(err, "query") {
+		t.Errorf("unexpected error %v", err)
+	}
+}